@@ -0,0 +1,60 @@
+// Command license-client-demo is a minimal example of embedding
+// license/client in a product: it starts a Client, prints its state on
+// every periodic revalidation, and reacts to Subscribe events.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"license-server/license/client"
+)
+
+func main() {
+	serverURL := flag.String("server", "http://localhost:8080", "license server base URL")
+	key := flag.String("key", "", "license key")
+	product := flag.String("product", "", "product name")
+	machineID := flag.String("machine-id", "", "machine ID to bind activation to (optional)")
+	refresh := flag.Duration("refresh", time.Minute, "how often to revalidate the license")
+	cachePath := flag.String("cache", "", "path to persist the last-known-good check (optional)")
+	flag.Parse()
+
+	if *key == "" || *product == "" {
+		fmt.Fprintln(os.Stderr, "usage: license-client-demo -key <key> -product <product> [-server URL]")
+		os.Exit(1)
+	}
+
+	c := client.New(client.Config{
+		ServerURL:       *serverURL,
+		Key:             *key,
+		Product:         *product,
+		MachineID:       *machineID,
+		RefreshInterval: *refresh,
+		CachePath:       *cachePath,
+	})
+
+	events := make(chan client.Event, 8)
+	c.Subscribe(events)
+	c.Start()
+	defer c.Stop()
+
+	log.Printf("initial check: valid=%v features=%v expires_at=%v", c.Valid(), c.Features(), c.ExpiresAt())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	for {
+		select {
+		case e := <-events:
+			log.Printf("event: %s (valid=%v features=%v)", e, c.Valid(), c.Features())
+		case <-sigCh:
+			log.Println("shutting down")
+			return
+		}
+	}
+}
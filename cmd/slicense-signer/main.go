@@ -0,0 +1,107 @@
+// Command slicense-signer mints and inspects signed license upload
+// artifacts for POST /api/license, entirely offline: the operator runs
+// this alongside a keypair they keep to themselves, and only ever hands
+// the server side (via license.LoadArtifactKeySet) the public half.
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"license-server/license"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = runKeygen(os.Args[2:])
+	case "sign":
+		err = runSign(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "slicense-signer:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: slicense-signer keygen <path>")
+	fmt.Fprintln(os.Stderr, "       slicense-signer sign -key <path> -kid <kid> -product <product> [flags]")
+}
+
+func runKeygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		return fmt.Errorf("usage: slicense-signer keygen <path>")
+	}
+
+	path := fs.Arg(0)
+	if err := license.GenerateKeyFiles(path); err != nil {
+		return err
+	}
+	fmt.Printf("keypair written: %s (private, keep this), %s.pub (public, give this to the server operator)\n", path, path)
+	return nil
+}
+
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to the private key written by 'keygen' (required)")
+	kid := fs.String("kid", "", "key id the server's ArtifactKeySet will look this key up under (required)")
+	product := fs.String("product", "", "product name (required)")
+	ownerEmail := fs.String("owner-email", "", "license owner's email (required)")
+	ownerName := fs.String("owner-name", "", "license owner's name (required)")
+	seats := fs.Int("seats", 0, "seat count (optional)")
+	features := fs.String("features", "", "comma-separated feature list (optional)")
+	ttl := fs.Duration("ttl", 0, "how long the license is valid for, e.g. 8760h (optional, default: no expiry)")
+	fs.Parse(args)
+
+	if *keyPath == "" || *kid == "" || *product == "" || *ownerEmail == "" || *ownerName == "" {
+		return fmt.Errorf("usage: slicense-signer sign -key <path> -kid <kid> -product <product> -owner-email <email> -owner-name <name> [-seats N] [-features a,b,c] [-ttl 8760h]")
+	}
+
+	raw, err := os.ReadFile(*keyPath)
+	if err != nil {
+		return fmt.Errorf("private key okunamadı: %w", err)
+	}
+	priv, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(priv) != ed25519.PrivateKeySize {
+		return fmt.Errorf("geçersiz private key: %s", *keyPath)
+	}
+
+	claims := license.UploadClaims{
+		Product:    *product,
+		OwnerEmail: *ownerEmail,
+		OwnerName:  *ownerName,
+		Seats:      *seats,
+	}
+	if *features != "" {
+		claims.Features = strings.Split(*features, ",")
+	}
+	if *ttl > 0 {
+		claims.ExpiresAt = time.Now().Add(*ttl).Unix()
+	}
+
+	artifact, err := license.SignArtifact(ed25519.PrivateKey(priv), *kid, claims)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(artifact)
+	return nil
+}
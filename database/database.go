@@ -14,8 +14,12 @@ type Database struct {
 }
 
 var (
-	ErrLicenseNotFound = errors.New("license not found")
-	ErrDuplicateKey    = errors.New("duplicate license key")
+	ErrLicenseNotFound        = errors.New("license not found")
+	ErrDuplicateKey           = errors.New("duplicate license key")
+	ErrMaxActivationsExceeded = errors.New("maximum activations exceeded")
+	ErrMachineNotActivated    = errors.New("machine not activated")
+	ErrLicenseNotRevoked      = errors.New("license not revoked")
+	ErrTicketNotFound         = errors.New("activation ticket not found")
 )
 
 func New(dsn string) (*Database, error) {
@@ -45,6 +49,11 @@ func createTables(db *sql.DB) error {
 			owner_email VARCHAR(255),
 			owner_name VARCHAR(255),
 			is_activated BOOLEAN DEFAULT FALSE,
+			token_signature TEXT NULL,
+			token_issued_at DATETIME NULL,
+			max_activations INT DEFAULT 0,
+			features TEXT NULL,
+			license_raw TEXT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			UNIQUE KEY uk_license_product (license_key, product)
@@ -54,6 +63,39 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("licenses table creation failed: %w", err)
 	}
 
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS machine_activations (
+			license_id INT NOT NULL,
+			machine_id VARCHAR(255) NOT NULL,
+			fingerprint VARCHAR(255),
+			first_seen DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_seen DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			ip VARCHAR(45),
+			hostname VARCHAR(255),
+			UNIQUE KEY uk_license_machine (license_id, machine_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+	`)
+	if err != nil {
+		return fmt.Errorf("machine_activations table creation failed: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS activation_tickets (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			license_id INT NOT NULL,
+			ticket_id VARCHAR(64) NOT NULL,
+			fingerprint_hash VARCHAR(64) NOT NULL,
+			issued_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			not_after DATETIME NOT NULL,
+			revoked_at DATETIME NULL,
+			UNIQUE KEY uk_ticket_id (ticket_id),
+			KEY idx_license_id (license_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+	`)
+	if err != nil {
+		return fmt.Errorf("activation_tickets table creation failed: %w", err)
+	}
+
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS Accounts (
 			id INT AUTO_INCREMENT PRIMARY KEY,
@@ -65,9 +107,28 @@ func createTables(db *sql.DB) error {
 			last_login_ip VARCHAR(45) NULL
 		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 	`)
-		if err != nil {
-			return fmt.Errorf("Accounts table creation failed: %w", err)
-		}
+	if err != nil {
+		return fmt.Errorf("Accounts table creation failed: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id VARCHAR(64) PRIMARY KEY,
+			user_id INT NOT NULL,
+			token_hash VARCHAR(64) NOT NULL,
+			prev_token_hash VARCHAR(64) NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at DATETIME NOT NULL,
+			revoked_at DATETIME NULL,
+			ip VARCHAR(45),
+			user_agent VARCHAR(255),
+			KEY idx_sessions_user_id (user_id),
+			KEY idx_sessions_token_hash (token_hash)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+	`)
+	if err != nil {
+		return fmt.Errorf("sessions table creation failed: %w", err)
+	}
 
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS audit_log (
@@ -85,10 +146,25 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("audit_log table creation failed: %w", err)
 	}
 
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS revocations (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			license_key VARCHAR(255) NOT NULL,
+			product VARCHAR(255) NOT NULL,
+			revoked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			reason VARCHAR(255),
+			UNIQUE KEY uk_revocation_product (license_key, product),
+			KEY idx_revoked_at (revoked_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+	`)
+	if err != nil {
+		return fmt.Errorf("revocations table creation failed: %w", err)
+	}
+
 	return nil
 }
 
-func (d *Database) AddLicense(key, product string, expiresAt *time.Time, ownerEmail, ownerName string) error {
+func (d *Database) AddLicense(key, product string, expiresAt *time.Time, ownerEmail, ownerName string, maxActivations int, features string) error {
 	tx, err := d.db.Begin()
 	if err != nil {
 		return fmt.Errorf("transaction begin failed: %w", err)
@@ -100,10 +176,10 @@ func (d *Database) AddLicense(key, product string, expiresAt *time.Time, ownerEm
 	}()
 
 	_, err = tx.Exec(
-		`INSERT INTO licenses 
-		(license_key, product, expires_at, owner_email, owner_name) 
-		VALUES (?, ?, ?, ?, ?)`,
-		key, product, expiresAt, ownerEmail, ownerName,
+		`INSERT INTO licenses
+		(license_key, product, expires_at, owner_email, owner_name, max_activations, features)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key, product, expiresAt, ownerEmail, ownerName, maxActivations, features,
 	)
 	if err != nil {
 		if isDuplicateKeyError(err) {
@@ -127,19 +203,44 @@ func (d *Database) AddLicense(key, product string, expiresAt *time.Time, ownerEm
 
 func (d *Database) GetLicense(key, product string) (*License, error) {
 	row := d.db.QueryRow(
-		`SELECT 
-			id, license_key, product, expires_at, 
-			owner_email, owner_name, is_activated 
-		FROM licenses 
+		`SELECT
+			id, license_key, product, expires_at,
+			owner_email, owner_name, is_activated,
+			token_signature, token_issued_at, max_activations, features, license_raw
+		FROM licenses
 		WHERE license_key = ? AND product = ?`,
 		key, product,
 	)
+	return scanLicense(row)
+}
+
+// GetLicenseByID looks up a license by its primary key, e.g. for the
+// DELETE /api/license/{id} admin flow where the operator only knows the
+// row ID an earlier list/upload response handed back.
+func (d *Database) GetLicenseByID(id int) (*License, error) {
+	row := d.db.QueryRow(
+		`SELECT
+			id, license_key, product, expires_at,
+			owner_email, owner_name, is_activated,
+			token_signature, token_issued_at, max_activations, features, license_raw
+		FROM licenses
+		WHERE id = ?`,
+		id,
+	)
+	return scanLicense(row)
+}
 
+func scanLicense(row *sql.Row) (*License, error) {
 	var lic License
 	var expiresAt sql.NullTime
+	var tokenSignature sql.NullString
+	var tokenIssuedAt sql.NullTime
+	var features sql.NullString
+	var licenseRaw sql.NullString
 	err := row.Scan(
 		&lic.ID, &lic.Key, &lic.Product, &expiresAt,
 		&lic.OwnerEmail, &lic.OwnerName, &lic.IsActivated,
+		&tokenSignature, &tokenIssuedAt, &lic.MaxActivations, &features, &licenseRaw,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -151,10 +252,414 @@ func (d *Database) GetLicense(key, product string) (*License, error) {
 	if expiresAt.Valid {
 		lic.ExpiresAt = &expiresAt.Time
 	}
+	if tokenSignature.Valid {
+		lic.TokenSignature = tokenSignature.String
+	}
+	if tokenIssuedAt.Valid {
+		lic.TokenIssuedAt = &tokenIssuedAt.Time
+	}
+	if features.Valid {
+		lic.Features = features.String
+	}
+	if licenseRaw.Valid {
+		lic.LicenseRaw = licenseRaw.String
+	}
 
 	return &lic, nil
 }
 
+// SetLicenseFeatures persists a license's serialized entitlements JSON and
+// records auditDetails (typically a human-readable diff of what changed)
+// in the same transaction.
+func (d *Database) SetLicenseFeatures(key, product, features, auditDetails string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(
+		`UPDATE licenses SET features = ? WHERE license_key = ? AND product = ?`,
+		features, key, product,
+	)
+	if err != nil {
+		return fmt.Errorf("license features update failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected check failed: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrLicenseNotFound
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log
+		(action, license_key, product, details)
+		VALUES (?, ?, ?, ?)`,
+		"UPDATE_ENTITLEMENTS", key, product, auditDetails,
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetLicenseToken persists the detached signature and issuance timestamp
+// of a signed offline license token issued for (key, product), so a
+// reissued token (or an audit trail of when licenses were last signed)
+// can be traced back through the licenses table.
+func (d *Database) SetLicenseToken(key, product, signature string, issuedAt time.Time) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(
+		`UPDATE licenses
+		SET token_signature = ?, token_issued_at = ?
+		WHERE license_key = ? AND product = ?`,
+		signature, issuedAt, key, product,
+	)
+	if err != nil {
+		return fmt.Errorf("license token update failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected check failed: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrLicenseNotFound
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log
+		(action, license_key, product)
+		VALUES (?, ?, ?)`,
+		"ISSUE_TOKEN", key, product,
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetLicenseRaw persists the original signed artifact a license was
+// created from, so the audit log's "UPLOAD" entry can be cross-checked
+// against exactly what the operator submitted rather than just the
+// parsed-out fields.
+func (d *Database) SetLicenseRaw(key, product, raw string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(
+		`UPDATE licenses SET license_raw = ? WHERE license_key = ? AND product = ?`,
+		raw, key, product,
+	)
+	if err != nil {
+		return fmt.Errorf("license raw update failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected check failed: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrLicenseNotFound
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log
+		(action, license_key, product)
+		VALUES (?, ?, ?)`,
+		"UPLOAD", key, product,
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ActivateMachine binds machineID to licenseID, refreshing last_seen if it
+// was already bound. Binding a new machine once CountActivations(licenseID)
+// has reached maxActivations (when maxActivations > 0) fails with
+// ErrMaxActivationsExceeded instead of creating the row.
+func (d *Database) ActivateMachine(licenseID int, key, product, machineID, fingerprint, ip, hostname string, maxActivations int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the license row for the duration of the transaction so two
+	// concurrent activations for the same license can't both read the
+	// same pre-insert count below and both squeak past maxActivations.
+	var lockedID int
+	if err = tx.QueryRow(`SELECT id FROM licenses WHERE id = ? FOR UPDATE`, licenseID).Scan(&lockedID); err != nil {
+		return fmt.Errorf("license lock failed: %w", err)
+	}
+
+	var existingMachineID string
+	err = tx.QueryRow(
+		`SELECT machine_id FROM machine_activations WHERE license_id = ? AND machine_id = ?`,
+		licenseID, machineID,
+	).Scan(&existingMachineID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("activation lookup failed: %w", err)
+	}
+	exists := err == nil
+	err = nil
+
+	if exists {
+		_, err = tx.Exec(
+			`UPDATE machine_activations
+			SET last_seen = CURRENT_TIMESTAMP, fingerprint = ?, ip = ?, hostname = ?
+			WHERE license_id = ? AND machine_id = ?`,
+			fingerprint, ip, hostname, licenseID, machineID,
+		)
+		if err != nil {
+			return fmt.Errorf("activation update failed: %w", err)
+		}
+	} else {
+		var count int
+		if err = tx.QueryRow(`SELECT COUNT(*) FROM machine_activations WHERE license_id = ?`, licenseID).Scan(&count); err != nil {
+			return fmt.Errorf("activation count failed: %w", err)
+		}
+		if maxActivations > 0 && count >= maxActivations {
+			err = ErrMaxActivationsExceeded
+			return err
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO machine_activations
+			(license_id, machine_id, fingerprint, ip, hostname)
+			VALUES (?, ?, ?, ?, ?)`,
+			licenseID, machineID, fingerprint, ip, hostname,
+		)
+		if err != nil {
+			return fmt.Errorf("activation insert failed: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log
+		(action, license_key, product, details)
+		VALUES (?, ?, ?, ?)`,
+		"ACTIVATE", key, product, fmt.Sprintf("machine_id: %s", machineID),
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeactivateMachine frees machineID's activation slot on licenseID.
+func (d *Database) DeactivateMachine(licenseID int, key, product, machineID string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(
+		`DELETE FROM machine_activations WHERE license_id = ? AND machine_id = ?`,
+		licenseID, machineID,
+	)
+	if err != nil {
+		return fmt.Errorf("activation delete failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected check failed: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrMachineNotActivated
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log
+		(action, license_key, product, details)
+		VALUES (?, ?, ?, ?)`,
+		"DEACTIVATE", key, product, fmt.Sprintf("machine_id: %s", machineID),
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListActivations returns every machine currently bound to licenseID.
+func (d *Database) ListActivations(licenseID int) ([]MachineActivation, error) {
+	rows, err := d.db.Query(
+		`SELECT license_id, machine_id, fingerprint, first_seen, last_seen, ip, hostname
+		FROM machine_activations
+		WHERE license_id = ?
+		ORDER BY first_seen`,
+		licenseID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("activation query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var activations []MachineActivation
+	for rows.Next() {
+		var a MachineActivation
+		var fingerprint, ip, hostname sql.NullString
+		if err := rows.Scan(&a.LicenseID, &a.MachineID, &fingerprint, &a.FirstSeen, &a.LastSeen, &ip, &hostname); err != nil {
+			return nil, fmt.Errorf("activation scan failed: %w", err)
+		}
+		a.Fingerprint = fingerprint.String
+		a.IP = ip.String
+		a.Hostname = hostname.String
+		activations = append(activations, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return activations, nil
+}
+
+// CountActivations returns how many machines are currently bound to
+// licenseID.
+func (d *Database) CountActivations(licenseID int) (int, error) {
+	var count int
+	err := d.db.QueryRow(`SELECT COUNT(*) FROM machine_activations WHERE license_id = ?`, licenseID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("activation count failed: %w", err)
+	}
+	return count, nil
+}
+
+// RecordActivationTicket inserts a row for a just-issued pkg/ticket
+// activation ticket and logs it to audit_log under the same "ACTIVATE"
+// action ActivateMachine uses, so both activation flows show up
+// together in the audit trail.
+func (d *Database) RecordActivationTicket(licenseID int, key, product, ticketID, fingerprintHash string, issuedAt, notAfter time.Time) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Exec(
+		`INSERT INTO activation_tickets
+		(license_id, ticket_id, fingerprint_hash, issued_at, not_after)
+		VALUES (?, ?, ?, ?, ?)`,
+		licenseID, ticketID, fingerprintHash, issuedAt, notAfter,
+	)
+	if err != nil {
+		return fmt.Errorf("activation ticket insert failed: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log
+		(action, license_key, product, details)
+		VALUES (?, ?, ?, ?)`,
+		"ACTIVATE", key, product, fmt.Sprintf("ticket_id: %s", ticketID),
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListActivationTickets returns every ticket issued for licenseID, most
+// recently issued first, so an admin can see which devices hold a live
+// ticket and revoke one specifically.
+func (d *Database) ListActivationTickets(licenseID int) ([]ActivationTicket, error) {
+	rows, err := d.db.Query(
+		`SELECT id, license_id, ticket_id, fingerprint_hash, issued_at, not_after, revoked_at
+		FROM activation_tickets
+		WHERE license_id = ?
+		ORDER BY issued_at DESC`,
+		licenseID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("activation ticket query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []ActivationTicket
+	for rows.Next() {
+		var t ActivationTicket
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&t.ID, &t.LicenseID, &t.TicketID, &t.FingerprintHash, &t.IssuedAt, &t.NotAfter, &revokedAt); err != nil {
+			return nil, fmt.Errorf("activation ticket scan failed: %w", err)
+		}
+		if revokedAt.Valid {
+			t.RevokedAt = &revokedAt.Time
+		}
+		tickets = append(tickets, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return tickets, nil
+}
+
+// RevokeActivationTicket marks ticketID revoked, failing with
+// ErrTicketNotFound if no such ticket exists.
+func (d *Database) RevokeActivationTicket(ticketID string) error {
+	result, err := d.db.Exec(
+		`UPDATE activation_tickets SET revoked_at = CURRENT_TIMESTAMP WHERE ticket_id = ? AND revoked_at IS NULL`,
+		ticketID,
+	)
+	if err != nil {
+		return fmt.Errorf("activation ticket revoke failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected check failed: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTicketNotFound
+	}
+	return nil
+}
+
 func (d *Database) DeleteLicense(key, product string) error {
 	tx, err := d.db.Begin()
 	if err != nil {
@@ -198,10 +703,10 @@ func (d *Database) DeleteLicense(key, product string) error {
 
 func (d *Database) ListLicenses(product string) ([]License, error) {
 	rows, err := d.db.Query(
-		`SELECT 
-			license_key, expires_at, 
-			owner_email, owner_name, is_activated 
-		FROM licenses 
+		`SELECT
+			id, license_key, expires_at,
+			owner_email, owner_name, is_activated, features
+		FROM licenses
 		WHERE product = ?`,
 		product,
 	)
@@ -214,15 +719,19 @@ func (d *Database) ListLicenses(product string) ([]License, error) {
 	for rows.Next() {
 		var lic License
 		var expiresAt sql.NullTime
+		var features sql.NullString
 		if err := rows.Scan(
-			&lic.Key, &expiresAt,
-			&lic.OwnerEmail, &lic.OwnerName, &lic.IsActivated,
+			&lic.ID, &lic.Key, &expiresAt,
+			&lic.OwnerEmail, &lic.OwnerName, &lic.IsActivated, &features,
 		); err != nil {
 			return nil, fmt.Errorf("license scan failed: %w", err)
 		}
 		if expiresAt.Valid {
 			lic.ExpiresAt = &expiresAt.Time
 		}
+		if features.Valid {
+			lic.Features = features.String
+		}
 		licenses = append(licenses, lic)
 	}
 
@@ -233,6 +742,95 @@ func (d *Database) ListLicenses(product string) ([]License, error) {
 	return licenses, nil
 }
 
+// StreamLicenses calls fn with every license row across every product, in
+// an unspecified order, scanning one row at a time off rows.Next() rather
+// than materializing them into a slice first — so a full-table export
+// doesn't hold the whole table in memory twice. fn returning an error
+// stops iteration and that error is returned.
+func (d *Database) StreamLicenses(fn func(License) error) error {
+	rows, err := d.db.Query(
+		`SELECT
+			id, license_key, product, expires_at,
+			owner_email, owner_name, is_activated,
+			token_signature, token_issued_at, max_activations, features, license_raw
+		FROM licenses`,
+	)
+	if err != nil {
+		return fmt.Errorf("license query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lic License
+		var expiresAt sql.NullTime
+		var tokenSignature sql.NullString
+		var tokenIssuedAt sql.NullTime
+		var features sql.NullString
+		var licenseRaw sql.NullString
+		if err := rows.Scan(
+			&lic.ID, &lic.Key, &lic.Product, &expiresAt,
+			&lic.OwnerEmail, &lic.OwnerName, &lic.IsActivated,
+			&tokenSignature, &tokenIssuedAt, &lic.MaxActivations, &features, &licenseRaw,
+		); err != nil {
+			return fmt.Errorf("license scan failed: %w", err)
+		}
+		if expiresAt.Valid {
+			lic.ExpiresAt = &expiresAt.Time
+		}
+		if tokenSignature.Valid {
+			lic.TokenSignature = tokenSignature.String
+		}
+		if tokenIssuedAt.Valid {
+			lic.TokenIssuedAt = &tokenIssuedAt.Time
+		}
+		if features.Valid {
+			lic.Features = features.String
+		}
+		if licenseRaw.Valid {
+			lic.LicenseRaw = licenseRaw.String
+		}
+
+		if err := fn(lic); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// StreamAuditLogs calls fn with every audit_log row, oldest first,
+// scanning one row at a time off rows.Next() for the same reason
+// StreamLicenses does. fn returning an error stops iteration and that
+// error is returned.
+func (d *Database) StreamAuditLogs(fn func(AuditLog) error) error {
+	rows, err := d.db.Query(
+		`SELECT
+			action, license_key, product,
+			changed_at, details
+		FROM audit_log
+		ORDER BY changed_at ASC`,
+	)
+	if err != nil {
+		return fmt.Errorf("audit log query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log AuditLog
+		if err := rows.Scan(
+			&log.Action, &log.LicenseKey, &log.Product,
+			&log.ChangedAt, &log.Details,
+		); err != nil {
+			return fmt.Errorf("audit log scan failed: %w", err)
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (d *Database) GetAuditLogs(limit int) ([]AuditLog, error) {
 	rows, err := d.db.Query(
 		`SELECT 
@@ -267,6 +865,154 @@ func (d *Database) GetAuditLogs(limit int) ([]AuditLog, error) {
 	return logs, nil
 }
 
+// RevokeLicense marks (key, product) revoked effective immediately,
+// independent of its expires_at, so a compromised or refunded key can be
+// killed without waiting for natural expiry. Revoking an
+// already-revoked license updates reason and revoked_at instead of
+// erroring, so re-running it with a corrected reason is safe.
+func (d *Database) RevokeLicense(key, product, reason, actor string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.Exec(
+		`INSERT INTO revocations (license_key, product, reason)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE reason = VALUES(reason), revoked_at = CURRENT_TIMESTAMP`,
+		key, product, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("revocation insert failed: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log (action, license_key, product, details)
+		VALUES (?, ?, ?, ?)`,
+		"REVOKE", key, product, fmt.Sprintf("reason: %s (by %s)", reason, actor),
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UnrevokeLicense reverses a prior RevokeLicense, failing with
+// ErrLicenseNotRevoked if (key, product) wasn't revoked.
+func (d *Database) UnrevokeLicense(key, product, actor string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(
+		`DELETE FROM revocations WHERE license_key = ? AND product = ?`,
+		key, product,
+	)
+	if err != nil {
+		return fmt.Errorf("revocation delete failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected check failed: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrLicenseNotRevoked
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log (action, license_key, product, details)
+		VALUES (?, ?, ?, ?)`,
+		"UNREVOKE", key, product, fmt.Sprintf("by %s", actor),
+	)
+	if err != nil {
+		return fmt.Errorf("audit log insert failed: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// IsRevoked returns (key, product)'s revocation record, or nil if it
+// isn't revoked.
+func (d *Database) IsRevoked(key, product string) (*Revocation, error) {
+	row := d.db.QueryRow(
+		`SELECT license_key, product, revoked_at, reason
+		FROM revocations
+		WHERE license_key = ? AND product = ?`,
+		key, product,
+	)
+
+	var rev Revocation
+	var reason sql.NullString
+	err := row.Scan(&rev.LicenseKey, &rev.Product, &rev.RevokedAt, &reason)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("revocation query failed: %w", err)
+	}
+	rev.Reason = reason.String
+
+	return &rev, nil
+}
+
+// ListRevocationsSince returns every revocation recorded after since,
+// oldest first, for CRL pull distribution.
+func (d *Database) ListRevocationsSince(since time.Time) ([]Revocation, error) {
+	rows, err := d.db.Query(
+		`SELECT license_key, product, revoked_at, reason
+		FROM revocations
+		WHERE revoked_at > ?
+		ORDER BY revoked_at`,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("revocation query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var revocations []Revocation
+	for rows.Next() {
+		var rev Revocation
+		var reason sql.NullString
+		if err := rows.Scan(&rev.LicenseKey, &rev.Product, &rev.RevokedAt, &reason); err != nil {
+			return nil, fmt.Errorf("revocation scan failed: %w", err)
+		}
+		rev.Reason = reason.String
+		revocations = append(revocations, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+
+	return revocations, nil
+}
+
+// LatestRevocationAt returns the most recent revoked_at across every
+// revocation, or the zero Time if none exist. The CRL endpoint uses this
+// to build an ETag/Last-Modified without re-querying the whole list on
+// every poll, since revocations only ever grow.
+func (d *Database) LatestRevocationAt() (time.Time, error) {
+	var latest sql.NullTime
+	if err := d.db.QueryRow(`SELECT MAX(revoked_at) FROM revocations`).Scan(&latest); err != nil {
+		return time.Time{}, fmt.Errorf("latest revocation query failed: %w", err)
+	}
+	return latest.Time, nil
+}
+
 func (d *Database) Close() error {
 	return d.db.Close()
 }
@@ -276,4 +1022,4 @@ func isDuplicateKeyError(err error) bool {
 		return false
 	}
 	return err.Error() == "Error 1062: Duplicate entry"
-}
\ No newline at end of file
+}
@@ -3,13 +3,58 @@ package database
 import "time"
 
 type License struct {
-	ID          int
-	Key         string
-	Product     string
-	ExpiresAt   *time.Time
-	OwnerEmail  string
-	OwnerName   string
-	IsActivated bool
+	ID             int
+	Key            string
+	Product        string
+	ExpiresAt      *time.Time
+	OwnerEmail     string
+	OwnerName      string
+	IsActivated    bool
+	TokenSignature string
+	TokenIssuedAt  *time.Time
+	MaxActivations int
+	Features       string
+	LicenseRaw     string
+}
+
+// MachineActivation binds one license to one machine. Fingerprint is an
+// opaque client-supplied hardware identifier beyond the MachineID itself
+// (e.g. a hash of CPU/disk/MAC details), used to help flag a machine that
+// reinstalls under a new MachineID.
+type MachineActivation struct {
+	LicenseID   int
+	MachineID   string
+	Fingerprint string
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	IP          string
+	Hostname    string
+}
+
+// ActivationTicket records the issuance of one pkg/ticket offline
+// activation ticket, so an admin can revoke a specific device (see
+// RevokeActivationTicket) without touching the license it was issued
+// for. Revoking a row here is bookkeeping only — it doesn't retroactively
+// invalidate a ticket a device already holds, since Verify never calls
+// back to the server; it just stops ListActivationTickets from showing
+// the device as active and is a record of the admin's intent.
+type ActivationTicket struct {
+	ID              int
+	LicenseID       int
+	TicketID        string
+	FingerprintHash string
+	IssuedAt        time.Time
+	NotAfter        time.Time
+	RevokedAt       *time.Time
+}
+
+// Revocation kills a license before its ExpiresAt — e.g. after a refund
+// or a compromised key — without deleting the license row itself.
+type Revocation struct {
+	LicenseKey string
+	Product    string
+	RevokedAt  time.Time
+	Reason     string
 }
 
 type AuditLog struct {
@@ -29,3 +74,20 @@ type Account struct {
 	LastLogin    *string
 	LastLoginIP  *string
 }
+
+// Session is a refresh-token-backed login session for an Account. Access
+// tokens are short-lived JWTs; the opaque refresh token is never stored in
+// plaintext, only as TokenHash. PrevTokenHash retains the hash rotated away
+// from on the last refresh, so a replay of that stale token can still be
+// recognized as reuse even though the session itself was never revoked.
+type Session struct {
+	ID            string
+	UserID        int
+	TokenHash     string
+	PrevTokenHash *string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	RevokedAt     *time.Time
+	IP            string
+	UserAgent     string
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSinkChainVerifiesIntact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := sink.Append(ctx, "license.issued", "admin", "license:abc", nil); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	if brk, err := sink.VerifyChain(ctx, 1, 0); err != ErrChainIntact || brk != nil {
+		t.Fatalf("expected an intact chain, got break=%+v err=%v", brk, err)
+	}
+}
+
+func TestFileSinkDetectsTamperedRow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := sink.Append(ctx, "license.issued", "admin", "license:abc", nil); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	sink.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	tampered := strings.Replace(string(data), `"resource":"license:abc"`, `"resource":"license:xyz"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	reopened, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink (reopen): %v", err)
+	}
+	defer reopened.Close()
+
+	brk, err := reopened.VerifyChain(ctx, 1, 0)
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if brk == nil || brk.Sequence != 1 {
+		t.Fatalf("expected a break at sequence 1, got %+v", brk)
+	}
+}
+
+func TestCanonicalJSONIsOrderIndependent(t *testing.T) {
+	entry := Entry{Action: "a", Actor: "b", Resource: "c", Details: map[string]interface{}{"z": 1, "a": 2}}
+	first, err := canonicalJSON(entry)
+	if err != nil {
+		t.Fatalf("canonicalJSON: %v", err)
+	}
+	second, err := canonicalJSON(entry)
+	if err != nil {
+		t.Fatalf("canonicalJSON: %v", err)
+	}
+	if string(first) != string(second) {
+		t.Fatal("expected canonicalJSON to be deterministic across calls")
+	}
+}
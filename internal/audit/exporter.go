@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// hostname returns the local hostname, or "-" (the RFC 5424 NILVALUE) if
+// it can't be determined.
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "-"
+	}
+	return name
+}
+
+// ExporterMode selects the transport Exporter ships batches over.
+type ExporterMode string
+
+const (
+	// ExporterModeHTTP POSTs each batch as a JSON array to HTTPURL.
+	ExporterModeHTTP ExporterMode = "http"
+	// ExporterModeSyslog frames each entry as an RFC 5424 message and
+	// writes it to SyslogAddr over TCP using RFC 6587 octet-counting.
+	ExporterModeSyslog ExporterMode = "syslog"
+)
+
+// ExporterConfig configures Exporter.
+type ExporterConfig struct {
+	Mode       ExporterMode
+	HTTPURL    string
+	SyslogAddr string
+	AppName    string // used as the RFC 5424 APP-NAME, defaults to "slicense"
+	BatchSize  int    // defaults to 100
+	Interval   time.Duration
+}
+
+// Exporter periodically ships new audit entries to an external SIEM so
+// license operators retain a forensic trail outside this process, even if
+// the local store is later tampered with or destroyed.
+type Exporter struct {
+	sink       Sink
+	cfg        ExporterConfig
+	httpClient *http.Client
+	cursor     int64
+}
+
+// NewExporter creates an Exporter reading from sink starting at the
+// beginning of the chain (cursor 0). Call Run in a goroutine.
+func NewExporter(sink Sink, cfg ExporterConfig) *Exporter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = "slicense"
+	}
+	return &Exporter{
+		sink:       sink,
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run ships batches on cfg.Interval until ctx is canceled. Export errors
+// are non-fatal: the cursor only advances on success, so a transient SIEM
+// outage is retried on the next tick without dropping entries.
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := e.exportOnce(ctx); err != nil {
+				continue
+			}
+		}
+	}
+}
+
+func (e *Exporter) exportOnce(ctx context.Context) error {
+	entries, err := e.sink.Since(ctx, e.cursor, e.cfg.BatchSize)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	switch e.cfg.Mode {
+	case ExporterModeSyslog:
+		err = e.exportSyslog(ctx, entries)
+	default:
+		err = e.exportHTTP(ctx, entries)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.cursor = entries[len(entries)-1].Sequence
+	return nil
+}
+
+func (e *Exporter) exportHTTP(ctx context.Context, entries []Entry) error {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("audit: batch marshal failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.cfg.HTTPURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("audit: export request build failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("audit: export request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("audit: export request returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *Exporter) exportSyslog(ctx context.Context, entries []Entry) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", e.cfg.SyslogAddr)
+	if err != nil {
+		return fmt.Errorf("audit: syslog dial failed: %w", err)
+	}
+	defer conn.Close()
+
+	for _, entry := range entries {
+		msg := formatRFC5424(entry, e.cfg.AppName)
+		// RFC 6587 octet-counting transport framing: "<length> <message>".
+		framed := fmt.Sprintf("%d %s", len(msg), msg)
+		if _, err := conn.Write([]byte(framed)); err != nil {
+			return fmt.Errorf("audit: syslog write failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatRFC5424 renders entry as an RFC 5424 syslog message. Facility is
+// fixed to 13 (log audit) and severity to 6 (informational), giving
+// PRI 110; structured data carries sequence/hash so a SIEM can reconcile
+// against the local chain without parsing the free-form msg.
+func formatRFC5424(entry Entry, appName string) string {
+	sd := fmt.Sprintf(`[audit seq="%d" prevHash="%s" hash="%s"]`, entry.Sequence, entry.PrevHash, entry.Hash)
+	msg, err := json.Marshal(struct {
+		Action   string                 `json:"action"`
+		Actor    string                 `json:"actor"`
+		Resource string                 `json:"resource"`
+		Details  map[string]interface{} `json:"details,omitempty"`
+	}{entry.Action, entry.Actor, entry.Resource, entry.Details})
+	if err != nil {
+		msg = []byte(entry.Action)
+	}
+
+	return fmt.Sprintf("<110>1 %s %s %s - audit %s %s",
+		entry.Timestamp.UTC().Format(time.RFC3339Nano), hostname(), appName, sd, msg)
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileSink is a Sink backed by a local append-only JSON-lines file. It
+// maintains its own hash chain independent of any MySQLSink, so it can be
+// used standalone (e.g. for an air-gapped instance) or as a secondary copy
+// via MultiSink.
+type FileSink struct {
+	mu       sync.Mutex
+	file     *os.File
+	lastSeq  int64
+	lastHash string
+}
+
+// NewFileSink opens (creating if necessary) path for appending and replays
+// it to recover the chain's current tip and sequence counter.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("audit: file sink open failed: %w", err)
+	}
+
+	s := &FileSink{file: file, lastHash: genesisHash}
+	if err := s.recoverTip(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) recoverTip() error {
+	scanner := bufio.NewScanner(s.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("audit: file sink replay failed: %w", err)
+		}
+		s.lastSeq = entry.Sequence
+		s.lastHash = entry.Hash
+	}
+	return scanner.Err()
+}
+
+// Append implements Sink.
+func (s *FileSink) Append(_ context.Context, action, actor, resource string, details map[string]interface{}) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := Entry{
+		Sequence: s.lastSeq + 1,
+		Action:   action,
+		Actor:    actor,
+		Resource: resource,
+		Details:  details,
+		PrevHash: s.lastHash,
+	}
+	entry.Timestamp = time.Now().UTC()
+
+	var err error
+	entry.Hash, err = computeHash(entry.PrevHash, entry)
+	if err != nil {
+		return Entry{}, fmt.Errorf("audit: hash computation failed: %w", err)
+	}
+
+	if err := s.appendLine(entry); err != nil {
+		return Entry{}, err
+	}
+
+	s.lastSeq = entry.Sequence
+	s.lastHash = entry.Hash
+	return entry, nil
+}
+
+func (s *FileSink) appendLine(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("audit: entry marshal failed: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("audit: entry write failed: %w", err)
+	}
+	return s.file.Sync()
+}
+
+// Since implements Sink by replaying the file from the start. FileSink is
+// meant for small/secondary deployments; this is O(n) in file size.
+func (s *FileSink) Since(_ context.Context, afterSeq int64, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("audit: file sink seek failed: %w", err)
+	}
+	defer s.file.Seek(0, 2)
+
+	var entries []Entry
+	scanner := bufio.NewScanner(s.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit: entry unmarshal failed: %w", err)
+		}
+		if entry.Sequence <= afterSeq {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) >= limit {
+			break
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// VerifyChain implements Sink by replaying the file and recomputing every
+// hash in order.
+func (s *FileSink) VerifyChain(_ context.Context, from, to int64) (*ChainBreak, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("audit: file sink seek failed: %w", err)
+	}
+	defer s.file.Seek(0, 2)
+
+	expectedPrev := genesisHash
+	scanner := bufio.NewScanner(s.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit: entry unmarshal failed: %w", err)
+		}
+
+		if entry.PrevHash != expectedPrev {
+			if entry.Sequence >= from && (to <= 0 || entry.Sequence <= to) {
+				return &ChainBreak{Sequence: entry.Sequence, Reason: "prev_hash does not match the preceding row's hash"}, nil
+			}
+		}
+		expectedPrev = entry.Hash
+
+		if entry.Sequence < from || (to > 0 && entry.Sequence > to) {
+			continue
+		}
+
+		wantHash, err := computeHash(entry.PrevHash, entry)
+		if err != nil {
+			return nil, fmt.Errorf("audit: hash recomputation failed: %w", err)
+		}
+		if wantHash != entry.Hash {
+			return &ChainBreak{Sequence: entry.Sequence, Reason: "stored hash does not match recomputed hash"}, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("audit: verify scan failed: %w", err)
+	}
+
+	return nil, ErrChainIntact
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,48 @@
+package audit
+
+import "context"
+
+// MultiSink writes every entry to a primary Sink (the source of truth for
+// sequencing, hashing and VerifyChain) and appends the same action, actor,
+// resource and details to one or more secondary sinks (e.g. a local
+// FileSink) for redundancy; each secondary assigns its own sequence,
+// timestamp and hash chain. A
+// secondary write failure is swallowed rather than failing Append, since
+// losing the mirror copy shouldn't block the primary audit trail.
+type MultiSink struct {
+	primary     Sink
+	secondaries []Sink
+	onMirrorErr func(secondary Sink, err error)
+}
+
+// NewMultiSink creates a MultiSink. onMirrorErr, if non-nil, is invoked
+// whenever writing to a secondary sink fails.
+func NewMultiSink(primary Sink, onMirrorErr func(secondary Sink, err error), secondaries ...Sink) *MultiSink {
+	return &MultiSink{primary: primary, secondaries: secondaries, onMirrorErr: onMirrorErr}
+}
+
+// Append implements Sink.
+func (m *MultiSink) Append(ctx context.Context, action, actor, resource string, details map[string]interface{}) (Entry, error) {
+	entry, err := m.primary.Append(ctx, action, actor, resource, details)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for _, secondary := range m.secondaries {
+		if _, mirrorErr := secondary.Append(ctx, action, actor, resource, details); mirrorErr != nil && m.onMirrorErr != nil {
+			m.onMirrorErr(secondary, mirrorErr)
+		}
+	}
+
+	return entry, nil
+}
+
+// Since implements Sink by delegating to the primary sink.
+func (m *MultiSink) Since(ctx context.Context, afterSeq int64, limit int) ([]Entry, error) {
+	return m.primary.Since(ctx, afterSeq, limit)
+}
+
+// VerifyChain implements Sink by delegating to the primary sink.
+func (m *MultiSink) VerifyChain(ctx context.Context, from, to int64) (*ChainBreak, error) {
+	return m.primary.VerifyChain(ctx, from, to)
+}
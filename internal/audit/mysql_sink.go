@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MySQLSink is the durable, source-of-truth Sink backed by an
+// append-only audit_chain table. Append serializes writers with a
+// row-locking SELECT ... FOR UPDATE on the current tip, so concurrent
+// writers still produce a single, strictly-increasing chain.
+type MySQLSink struct {
+	db *sql.DB
+}
+
+// NewMySQLSink creates a MySQLSink and ensures its backing table exists.
+func NewMySQLSink(db *sql.DB) (*MySQLSink, error) {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_chain (
+			sequence BIGINT AUTO_INCREMENT PRIMARY KEY,
+			timestamp DATETIME(6) NOT NULL,
+			action VARCHAR(100) NOT NULL,
+			actor VARCHAR(255) NOT NULL,
+			resource VARCHAR(255) NOT NULL,
+			details JSON NULL,
+			prev_hash CHAR(64) NOT NULL,
+			hash CHAR(64) NOT NULL,
+			KEY idx_audit_chain_action (action),
+			KEY idx_audit_chain_actor (actor)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+	`); err != nil {
+		return nil, fmt.Errorf("audit_chain table creation failed: %w", err)
+	}
+	return &MySQLSink{db: db}, nil
+}
+
+// Append implements Sink.
+func (s *MySQLSink) Append(ctx context.Context, action, actor, resource string, details map[string]interface{}) (Entry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Entry{}, fmt.Errorf("audit: transaction begin failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var prevSeq int64
+	prevHash := genesisHash
+	row := tx.QueryRowContext(ctx, `SELECT sequence, hash FROM audit_chain ORDER BY sequence DESC LIMIT 1 FOR UPDATE`)
+	if scanErr := row.Scan(&prevSeq, &prevHash); scanErr != nil && scanErr != sql.ErrNoRows {
+		err = scanErr
+		return Entry{}, fmt.Errorf("audit: tip lookup failed: %w", err)
+	}
+
+	// The tip row is locked FOR UPDATE above, so reserving the next
+	// sequence number here (rather than trusting LastInsertId() after
+	// the fact) is safe from concurrent writers, and lets entry.Sequence
+	// be part of what gets hashed below — matching FileSink.Append,
+	// whose hash likewise covers the sequence it assigns up front.
+	entry := Entry{
+		Sequence: prevSeq + 1,
+		Action:   action,
+		Actor:    actor,
+		Resource: resource,
+		Details:  details,
+		PrevHash: prevHash,
+	}
+	entry.Timestamp, err = currentTimestamp(ctx, tx)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry.Hash, err = computeHash(prevHash, entry)
+	if err != nil {
+		return Entry{}, fmt.Errorf("audit: hash computation failed: %w", err)
+	}
+
+	detailsJSON, err := json.Marshal(entry.Details)
+	if err != nil {
+		return Entry{}, fmt.Errorf("audit: details marshal failed: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO audit_chain (sequence, timestamp, action, actor, resource, details, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Sequence, entry.Timestamp, entry.Action, entry.Actor, entry.Resource, detailsJSON, entry.PrevHash, entry.Hash,
+	)
+	if err != nil {
+		return Entry{}, fmt.Errorf("audit: insert failed: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return Entry{}, fmt.Errorf("audit: commit failed: %w", err)
+	}
+
+	return entry, nil
+}
+
+// currentTimestamp asks MySQL for NOW(6) inside tx so every writer's
+// notion of "now" agrees with the row order MySQL itself assigns.
+func currentTimestamp(ctx context.Context, tx *sql.Tx) (t time.Time, err error) {
+	err = tx.QueryRowContext(ctx, `SELECT NOW(6)`).Scan(&t)
+	return t, err
+}
+
+// Since implements Sink.
+func (s *MySQLSink) Since(ctx context.Context, afterSeq int64, limit int) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT sequence, timestamp, action, actor, resource, details, prev_hash, hash
+		FROM audit_chain WHERE sequence > ? ORDER BY sequence ASC LIMIT ?`,
+		afterSeq, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("audit: since query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		entry, detailsJSON, scanErr := scanChainRow(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		if len(detailsJSON) > 0 {
+			if err := json.Unmarshal(detailsJSON, &entry.Details); err != nil {
+				return nil, fmt.Errorf("audit: details unmarshal failed: %w", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// VerifyChain implements Sink.
+func (s *MySQLSink) VerifyChain(ctx context.Context, from, to int64) (*ChainBreak, error) {
+	query := `SELECT sequence, timestamp, action, actor, resource, details, prev_hash, hash
+		FROM audit_chain WHERE sequence >= ?`
+	args := []interface{}{from}
+	if to > 0 {
+		query += ` AND sequence <= ?`
+		args = append(args, to)
+	}
+	query += ` ORDER BY sequence ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("audit: verify query failed: %w", err)
+	}
+	defer rows.Close()
+
+	expectedPrev := ""
+	haveExpectedPrev := false
+	for rows.Next() {
+		entry, detailsJSON, scanErr := scanChainRow(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		if len(detailsJSON) > 0 {
+			if err := json.Unmarshal(detailsJSON, &entry.Details); err != nil {
+				return nil, fmt.Errorf("audit: details unmarshal failed: %w", err)
+			}
+		}
+
+		if haveExpectedPrev && entry.PrevHash != expectedPrev {
+			return &ChainBreak{Sequence: entry.Sequence, Reason: "prev_hash does not match the preceding row's hash"}, nil
+		}
+
+		wantHash, err := computeHash(entry.PrevHash, entry)
+		if err != nil {
+			return nil, fmt.Errorf("audit: hash recomputation failed: %w", err)
+		}
+		if wantHash != entry.Hash {
+			return &ChainBreak{Sequence: entry.Sequence, Reason: "stored hash does not match recomputed hash"}, nil
+		}
+
+		expectedPrev = entry.Hash
+		haveExpectedPrev = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("audit: verify rows iteration failed: %w", err)
+	}
+
+	return nil, ErrChainIntact
+}
+
+func scanChainRow(rows *sql.Rows) (Entry, []byte, error) {
+	var entry Entry
+	var detailsJSON []byte
+	err := rows.Scan(&entry.Sequence, &entry.Timestamp, &entry.Action, &entry.Actor, &entry.Resource, &detailsJSON, &entry.PrevHash, &entry.Hash)
+	if err != nil {
+		return Entry{}, nil, fmt.Errorf("audit: row scan failed: %w", err)
+	}
+	return entry, detailsJSON, nil
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+// appendViaMock drives one MySQLSink.Append call against mock, returning
+// the stored Entry. It exists because every Append issues the same four
+// statements in the same order (tip lookup, NOW(6), insert, commit), and
+// repeating that sequence inline would dwarf the tests that use it.
+func appendViaMock(t *testing.T, sink *MySQLSink, mock sqlmock.Sqlmock, prev *Entry, action, actor, resource string) Entry {
+	t.Helper()
+
+	mock.ExpectBegin()
+	tipRows := sqlmock.NewRows([]string{"sequence", "hash"})
+	if prev != nil {
+		tipRows.AddRow(prev.Sequence, prev.Hash)
+	}
+	mock.ExpectQuery("SELECT sequence, hash FROM audit_chain").WillReturnRows(tipRows)
+	mock.ExpectQuery(`SELECT NOW\(6\)`).WillReturnRows(
+		sqlmock.NewRows([]string{"NOW(6)"}).AddRow(time.Now().UTC()),
+	)
+	mock.ExpectExec("INSERT INTO audit_chain").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	entry, err := sink.Append(context.Background(), action, actor, resource, nil)
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	return entry
+}
+
+// verifyRowsFor builds the rows VerifyChain's SELECT would return for
+// entries, mirroring exactly what MySQLSink.Append persisted for each one.
+func verifyRowsFor(t *testing.T, entries []Entry) *sqlmock.Rows {
+	t.Helper()
+
+	rows := sqlmock.NewRows([]string{"sequence", "timestamp", "action", "actor", "resource", "details", "prev_hash", "hash"})
+	for _, e := range entries {
+		detailsJSON, err := json.Marshal(e.Details)
+		if err != nil {
+			t.Fatalf("Marshal details: %v", err)
+		}
+		rows.AddRow(e.Sequence, e.Timestamp, e.Action, e.Actor, e.Resource, detailsJSON, e.PrevHash, e.Hash)
+	}
+	return rows
+}
+
+// TestMySQLSinkChainVerifiesIntact guards against the bug where
+// entry.Hash was computed before entry.Sequence was assigned from
+// LastInsertId(), guaranteeing every later VerifyChain/Since read
+// recomputed a different hash than the one actually stored. Sequence
+// must be reserved (from the FOR UPDATE-locked tip row) and hashed
+// before the insert, exactly like FileSink.Append reserves lastSeq+1.
+func TestMySQLSinkChainVerifiesIntact(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	sink := &MySQLSink{db: db}
+
+	var entries []Entry
+	var prev *Entry
+	for i := 0; i < 3; i++ {
+		entry := appendViaMock(t, sink, mock, prev, "license.issued", "admin", "license:abc")
+		entries = append(entries, entry)
+		prev = &entries[len(entries)-1]
+	}
+
+	mock.ExpectQuery("SELECT sequence, timestamp, action, actor, resource, details, prev_hash, hash").
+		WillReturnRows(verifyRowsFor(t, entries))
+
+	brk, err := sink.VerifyChain(context.Background(), 1, 0)
+	if err != ErrChainIntact || brk != nil {
+		t.Fatalf("expected an intact chain, got break=%+v err=%v", brk, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+// TestMySQLSinkDetectsTamperedRow confirms a row edited directly in MySQL
+// (bypassing Append) is caught by VerifyChain, the same guarantee
+// TestFileSinkDetectsTamperedRow checks for FileSink.
+func TestMySQLSinkDetectsTamperedRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	sink := &MySQLSink{db: db}
+
+	entry := appendViaMock(t, sink, mock, nil, "license.issued", "admin", "license:abc")
+	entry.Resource = "license:xyz"
+
+	mock.ExpectQuery("SELECT sequence, timestamp, action, actor, resource, details, prev_hash, hash").
+		WillReturnRows(verifyRowsFor(t, []Entry{entry}))
+
+	brk, err := sink.VerifyChain(context.Background(), 1, 0)
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if brk == nil || brk.Sequence != 1 {
+		t.Fatalf("expected a break at sequence 1, got %+v", brk)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package jwtauth verifies bearer JWTs against one or more trusted
+// issuers — the server's own EdDSA-signed session tokens alongside any
+// number of externally-hosted RS256/ES256 issuers discovered via JWKS —
+// and checks a verified token's jti against a revocation list before the
+// caller treats it as valid. It is framework-agnostic; web/middleware
+// wires it into an HTTP middleware.
+package jwtauth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Claims is the subset of a verified JWT's claims this package exposes,
+// normalized across EdDSA local tokens and JWKS-verified external ones.
+type Claims struct {
+	Subject  string
+	Issuer   string
+	Audience []string
+	ID       string // jti, used for revocation lookups
+	Scopes   []string
+	Roles    []string
+}
+
+// HasScope reports whether scope is present in Claims.Scopes.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Reason names why a token was rejected. It drives both the
+// WWW-Authenticate challenge and the choice between a 401 and a 403.
+type Reason string
+
+const (
+	ReasonMalformed         Reason = "malformed"
+	ReasonBadSignature      Reason = "bad_signature"
+	ReasonExpired           Reason = "expired"
+	ReasonWrongAudience     Reason = "wrong_audience"
+	ReasonUnknownIssuer     Reason = "unknown_issuer"
+	ReasonRevoked           Reason = "revoked"
+	ReasonInsufficientScope Reason = "insufficient_scope"
+)
+
+// VerifyError reports why a token failed verification or authorization.
+// Reason is always set; Err, when present, carries the underlying cause
+// for logging.
+type VerifyError struct {
+	Reason Reason
+	Err    error
+}
+
+func (e *VerifyError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("jwtauth: %s: %v", e.Reason, e.Err)
+	}
+	return fmt.Sprintf("jwtauth: %s", e.Reason)
+}
+
+func (e *VerifyError) Unwrap() error { return e.Err }
+
+func newVerifyError(reason Reason, err error) *VerifyError {
+	return &VerifyError{Reason: reason, Err: err}
+}
+
+// Verifier validates a bearer token string and returns its claims, or a
+// *VerifyError explaining why it was rejected.
+type Verifier interface {
+	Verify(ctx context.Context, tokenString string) (*Claims, error)
+}
@@ -0,0 +1,280 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestLRURevocationCheckerCachesAndEvicts(t *testing.T) {
+	calls := 0
+	inner := revocationCheckerFunc(func(_ context.Context, jti string) (bool, error) {
+		calls++
+		return jti == "revoked-1", nil
+	})
+
+	cache := NewLRURevocationChecker(inner, 2, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		revoked, err := cache.IsRevoked(context.Background(), "revoked-1")
+		if err != nil {
+			t.Fatalf("IsRevoked: %v", err)
+		}
+		if !revoked {
+			t.Fatal("expected revoked-1 to be revoked")
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the inner checker to be called once (cached after), got %d calls", calls)
+	}
+
+	if revoked, err := cache.IsRevoked(context.Background(), "clean-1"); err != nil || revoked {
+		t.Fatalf("IsRevoked(clean-1) = %v, %v, want false, nil", revoked, err)
+	}
+}
+
+func TestLRURevocationCheckerExpiresEntries(t *testing.T) {
+	calls := 0
+	inner := revocationCheckerFunc(func(_ context.Context, _ string) (bool, error) {
+		calls++
+		return true, nil
+	})
+
+	cache := NewLRURevocationChecker(inner, 10, time.Millisecond)
+
+	if _, err := cache.IsRevoked(context.Background(), "jti-1"); err != nil {
+		t.Fatalf("IsRevoked: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := cache.IsRevoked(context.Background(), "jti-1"); err != nil {
+		t.Fatalf("IsRevoked: %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected a re-check after the entry's ttl expired, got %d calls", calls)
+	}
+}
+
+func TestLRURevocationCheckerBoundsEntryCount(t *testing.T) {
+	inner := revocationCheckerFunc(func(_ context.Context, _ string) (bool, error) { return false, nil })
+	cache := NewLRURevocationChecker(inner, 2, time.Minute)
+
+	for _, jti := range []string{"a", "b", "c"} {
+		if _, err := cache.IsRevoked(context.Background(), jti); err != nil {
+			t.Fatalf("IsRevoked(%s): %v", jti, err)
+		}
+	}
+
+	cache.mu.Lock()
+	n := len(cache.entries)
+	cache.mu.Unlock()
+
+	if n > 2 {
+		t.Fatalf("expected at most 2 cached entries after eviction, got %d", n)
+	}
+}
+
+type revocationCheckerFunc func(ctx context.Context, jti string) (bool, error)
+
+func (f revocationCheckerFunc) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return f(ctx, jti)
+}
+
+// jwksTestServer serves a JWKS containing pub's RSA public key under kid,
+// for JWKSVerifier tests that need a real HTTP round trip.
+func jwksTestServer(t *testing.T, kid string, pub *rsa.PublicKey) *httptest.Server {
+	t.Helper()
+
+	set := jwkSet{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(bigEndianBytes(pub.E)),
+	}}}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+}
+
+func bigEndianBytes(n int) []byte {
+	b := []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+	i := 0
+	for i < len(b)-1 && b[i] == 0 {
+		i++
+	}
+	return b[i:]
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing test token: %v", err)
+	}
+	return signed
+}
+
+func TestJWKSVerifierVerifiesValidToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+
+	server := jwksTestServer(t, "key-1", &key.PublicKey)
+	defer server.Close()
+
+	trust := IssuerTrust{Issuer: "https://idp.example.com", JWKSURL: server.URL, Audience: "license-server"}
+	verifier := NewJWKSVerifier([]IssuerTrust{trust}, time.Minute)
+	defer verifier.Close()
+
+	tokenStr := signRS256(t, key, "key-1", jwt.MapClaims{
+		"iss":   trust.Issuer,
+		"aud":   trust.Audience,
+		"sub":   "partner-user-42",
+		"jti":   "abc-123",
+		"scope": "license:read license:verify",
+		"roles": []interface{}{"partner"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	claims, err := verifier.Verify(context.Background(), tokenStr)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "partner-user-42" {
+		t.Fatalf("Subject = %q, want %q", claims.Subject, "partner-user-42")
+	}
+	if !claims.HasScope("license:verify") {
+		t.Fatalf("expected scope license:verify in %v", claims.Scopes)
+	}
+	if len(claims.Roles) != 1 || claims.Roles[0] != "partner" {
+		t.Fatalf("Roles = %v, want [partner]", claims.Roles)
+	}
+}
+
+func TestJWKSVerifierRejectsExpiredToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+
+	server := jwksTestServer(t, "key-1", &key.PublicKey)
+	defer server.Close()
+
+	trust := IssuerTrust{Issuer: "https://idp.example.com", JWKSURL: server.URL, Audience: "license-server"}
+	verifier := NewJWKSVerifier([]IssuerTrust{trust}, time.Minute)
+	defer verifier.Close()
+
+	tokenStr := signRS256(t, key, "key-1", jwt.MapClaims{
+		"iss": trust.Issuer,
+		"aud": trust.Audience,
+		"sub": "partner-user-42",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+
+	_, err = verifier.Verify(context.Background(), tokenStr)
+	var verr *VerifyError
+	if !errors.As(err, &verr) || verr.Reason != ReasonExpired {
+		t.Fatalf("Verify error = %v, want a VerifyError with Reason %q", err, ReasonExpired)
+	}
+}
+
+func TestJWKSVerifierRejectsWrongAudience(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+
+	server := jwksTestServer(t, "key-1", &key.PublicKey)
+	defer server.Close()
+
+	trust := IssuerTrust{Issuer: "https://idp.example.com", JWKSURL: server.URL, Audience: "license-server"}
+	verifier := NewJWKSVerifier([]IssuerTrust{trust}, time.Minute)
+	defer verifier.Close()
+
+	tokenStr := signRS256(t, key, "key-1", jwt.MapClaims{
+		"iss": trust.Issuer,
+		"aud": "someone-else",
+		"sub": "partner-user-42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	_, err = verifier.Verify(context.Background(), tokenStr)
+	var verr *VerifyError
+	if !errors.As(err, &verr) || verr.Reason != ReasonWrongAudience {
+		t.Fatalf("Verify error = %v, want a VerifyError with Reason %q", err, ReasonWrongAudience)
+	}
+}
+
+func TestJWKSVerifierRejectsUntrustedIssuer(t *testing.T) {
+	verifier := NewJWKSVerifier(nil, time.Minute)
+	defer verifier.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	tokenStr := signRS256(t, key, "key-1", jwt.MapClaims{"iss": "https://untrusted.example.com"})
+
+	_, err = verifier.Verify(context.Background(), tokenStr)
+	var verr *VerifyError
+	if !errors.As(err, &verr) || verr.Reason != ReasonUnknownIssuer {
+		t.Fatalf("Verify error = %v, want a VerifyError with Reason %q", err, ReasonUnknownIssuer)
+	}
+}
+
+type fixedVerifier struct {
+	claims *Claims
+	err    error
+}
+
+func (f fixedVerifier) Verify(_ context.Context, _ string) (*Claims, error) {
+	return f.claims, f.err
+}
+
+func TestMultiVerifierRoutesByIssuer(t *testing.T) {
+	local := fixedVerifier{claims: &Claims{Subject: "1"}}
+	multi := &MultiVerifier{Local: local, JWKS: NewJWKSVerifier(nil, time.Minute)}
+	defer multi.JWKS.Close()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{}).SignedString([]byte("irrelevant"))
+	if err != nil {
+		t.Fatalf("signing local-shaped token: %v", err)
+	}
+
+	claims, err := multi.Verify(context.Background(), signed)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "1" {
+		t.Fatalf("expected the request to be routed to the local verifier, got claims %+v", claims)
+	}
+}
+
+func TestMultiVerifierRejectsUnknownIssuerWithoutJWKS(t *testing.T) {
+	multi := &MultiVerifier{}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": "https://somewhere.example.com"}).SignedString([]byte("irrelevant"))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+
+	_, err = multi.Verify(context.Background(), signed)
+	var verr *VerifyError
+	if !errors.As(err, &verr) || verr.Reason != ReasonUnknownIssuer {
+		t.Fatalf("Verify error = %v, want a VerifyError with Reason %q", err, ReasonUnknownIssuer)
+	}
+}
@@ -0,0 +1,47 @@
+package jwtauth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MultiVerifier dispatches a token to the verifier that trusts its
+// issuer: Local handles the server's own tokens (which carry no "iss", or
+// LocalIssuer if set), everything else is routed to JWKS. Either may be
+// nil to disable that path.
+type MultiVerifier struct {
+	Local       Verifier
+	LocalIssuer string
+	JWKS        *JWKSVerifier
+}
+
+// Verify implements Verifier.
+func (m *MultiVerifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	issuer, err := peekIssuer(tokenString)
+	if err != nil {
+		return nil, newVerifyError(ReasonMalformed, err)
+	}
+
+	if (issuer == "" || issuer == m.LocalIssuer) && m.Local != nil {
+		return m.Local.Verify(ctx, tokenString)
+	}
+	if m.JWKS != nil {
+		return m.JWKS.Verify(ctx, tokenString)
+	}
+	return nil, newVerifyError(ReasonUnknownIssuer, fmt.Errorf("issuer %q is not trusted", issuer))
+}
+
+// peekIssuer reads the "iss" claim without verifying the token's
+// signature, just enough to pick the right verifier for the real,
+// signature-checking pass.
+func peekIssuer(tokenString string) (string, error) {
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return "", err
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	issuer, _ := claims.GetIssuer()
+	return issuer, nil
+}
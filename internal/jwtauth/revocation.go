@@ -0,0 +1,132 @@
+package jwtauth
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationChecker reports whether the token identified by jti has been
+// revoked (logout-all, a compromised key, an admin ban, ...), so a still
+// cryptographically-valid token can be rejected before its exp.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// RedisRevocationChecker checks a Redis key, populated out-of-band (e.g.
+// by session revocation or an admin action), namespaced under keyPrefix
+// and keyed by jti.
+type RedisRevocationChecker struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisRevocationChecker creates a RedisRevocationChecker namespacing
+// its keys under keyPrefix (e.g. "jwt_revoked").
+func NewRedisRevocationChecker(client *redis.Client, keyPrefix string) *RedisRevocationChecker {
+	return &RedisRevocationChecker{client: client, keyPrefix: keyPrefix}
+}
+
+// IsRevoked implements RevocationChecker.
+func (r *RedisRevocationChecker) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	n, err := r.client.Exists(ctx, fmt.Sprintf("%s:%s", r.keyPrefix, jti)).Result()
+	if err != nil {
+		return false, fmt.Errorf("revocation check failed: %w", err)
+	}
+	return n > 0, nil
+}
+
+// LRURevocationChecker wraps another RevocationChecker with a bounded,
+// TTL-expiring in-process cache so a hot token's revocation status isn't
+// re-checked against Redis on every request. Entries are LRU-evicted once
+// maxEntries is exceeded, the same bounding strategy ratelimit.MemoryLimiter
+// uses for its per-key limiters.
+type LRURevocationChecker struct {
+	inner RevocationChecker
+	ttl   time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	maxEntries int
+}
+
+type revocationEntry struct {
+	jti       string
+	revoked   bool
+	expiresAt time.Time
+}
+
+// NewLRURevocationChecker wraps inner with a cache bounded to maxEntries
+// keys, each entry valid for ttl before being re-checked against inner.
+func NewLRURevocationChecker(inner RevocationChecker, maxEntries int, ttl time.Duration) *LRURevocationChecker {
+	return &LRURevocationChecker{
+		inner:      inner,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// IsRevoked implements RevocationChecker.
+func (c *LRURevocationChecker) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	if revoked, ok := c.lookup(jti); ok {
+		return revoked, nil
+	}
+
+	revoked, err := c.inner.IsRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	c.store(jti, revoked)
+	return revoked, nil
+}
+
+func (c *LRURevocationChecker) lookup(jti string) (revoked bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[jti]
+	if !ok {
+		return false, false
+	}
+	entry := el.Value.(*revocationEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, jti)
+		return false, false
+	}
+	c.order.MoveToFront(el)
+	return entry.revoked, true
+}
+
+func (c *LRURevocationChecker) store(jti string, revoked bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &revocationEntry{jti: jti, revoked: revoked, expiresAt: time.Now().Add(c.ttl)}
+	el := c.order.PushFront(entry)
+	c.entries[jti] = el
+
+	if c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		if oldest != nil {
+			oldestEntry := oldest.Value.(*revocationEntry)
+			c.order.Remove(oldest)
+			delete(c.entries, oldestEntry.jti)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ElasticsearchTransport ships batches to an Elasticsearch (or
+// OpenSearch) _bulk endpoint using the newline-delimited bulk format.
+type ElasticsearchTransport struct {
+	client  *http.Client
+	bulkURL string
+	index   string
+}
+
+// NewElasticsearchTransport creates an ElasticsearchTransport posting to
+// bulkURL (e.g. "http://es:9200/_bulk"), indexing every entry into index.
+func NewElasticsearchTransport(bulkURL, index string) *ElasticsearchTransport {
+	return &ElasticsearchTransport{client: newTransportHTTPClient(nil), bulkURL: bulkURL, index: index}
+}
+
+type esBulkAction struct {
+	Index esBulkIndex `json:"index"`
+}
+
+type esBulkIndex struct {
+	Index string `json:"_index"`
+}
+
+// Send implements LogTransport.
+func (t *ElasticsearchTransport) Send(ctx context.Context, entries []LogEntry) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		action, err := json.Marshal(esBulkAction{Index: esBulkIndex{Index: t.index}})
+		if err != nil {
+			return fmt.Errorf("elasticsearch: action marshal failed: %w", err)
+		}
+		doc, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("elasticsearch: entry marshal failed: %w", err)
+		}
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(doc)
+		buf.WriteByte('\n')
+	}
+
+	return postGzipJSON(ctx, t.client, t.bulkURL, "application/x-ndjson", buf.Bytes(), nil)
+}
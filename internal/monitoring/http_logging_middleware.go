@@ -0,0 +1,198 @@
+package monitoring
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ReproducerConfig controls RequestTracingMiddleware's capture behavior.
+type ReproducerConfig struct {
+	// MaxBodyBytes bounds how much of the request/response body is teed
+	// into the log record. Bytes beyond the limit still flow through to the
+	// handler/client untouched, so large uploads aren't held in memory.
+	MaxBodyBytes int64
+	// HeaderDenylist lists header names (case-insensitive) whose values are
+	// replaced with "REDACTED" before logging or building the curl command.
+	HeaderDenylist []string
+}
+
+// DefaultReproducerConfig returns a 64KB body cap and redaction of the
+// headers that carry credentials.
+func DefaultReproducerConfig() ReproducerConfig {
+	return ReproducerConfig{
+		MaxBodyBytes:   64 * 1024,
+		HeaderDenylist: []string{"Authorization", "X-Csrf-Token", "Cookie", "Set-Cookie"},
+	}
+}
+
+// RequestTracingMiddleware captures full request/response context - method,
+// URL, headers, query params, and bounded body snippets - and logs it as a
+// single debug-level record an operator can replay with curl, so a customer
+// reporting a failed license verification/activation doesn't need to
+// reproduce it themselves. It only captures when the logger is configured
+// for debug level, so it's safe to leave mounted in production.
+func RequestTracingMiddleware(logger *Logger, cfg ReproducerConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !logger.Core().Enabled(zapcore.DebugLevel) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requestID := r.Header.Get("X-Request-ID")
+
+			var reqBody bytes.Buffer
+			if r.Body != nil {
+				r.Body = &boundedTeeReadCloser{rc: r.Body, buf: &reqBody, limit: cfg.MaxBodyBytes}
+			}
+
+			rec := &bodyCapturingWriter{ResponseWriter: w, limit: cfg.MaxBodyBytes, status: http.StatusOK}
+
+			start := time.Now()
+			next.ServeHTTP(rec, r)
+			duration := time.Since(start)
+
+			logger.Debug("http request reproducer",
+				zap.String("request_id", requestID),
+				zap.String("method", r.Method),
+				zap.String("url", r.URL.String()),
+				zap.String("remote_addr", requestClientIP(r)),
+				zap.Any("request_headers", redactHeaders(r.Header, cfg.HeaderDenylist)),
+				zap.String("request_body", reqBody.String()),
+				zap.Int("status", rec.status),
+				zap.Any("response_headers", redactHeaders(w.Header(), cfg.HeaderDenylist)),
+				zap.String("response_body", rec.buf.String()),
+				zap.Duration("duration", duration),
+				zap.String("curl", buildCurlReproducer(r, reqBody.Bytes(), cfg.HeaderDenylist)),
+			)
+		})
+	}
+}
+
+// boundedTeeReadCloser tees reads into buf up to limit bytes, so a large
+// request body still streams to the handler without being held in memory
+// beyond the cap.
+type boundedTeeReadCloser struct {
+	rc    io.ReadCloser
+	buf   *bytes.Buffer
+	limit int64
+}
+
+func (t *boundedTeeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.rc.Read(p)
+	if n > 0 {
+		capBuf(t.buf, p[:n], t.limit)
+	}
+	return n, err
+}
+
+func (t *boundedTeeReadCloser) Close() error {
+	return t.rc.Close()
+}
+
+// bodyCapturingWriter wraps an http.ResponseWriter to capture the status
+// code and a bounded snippet of the response body.
+type bodyCapturingWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	limit       int64
+	status      int
+	wroteHeader bool
+}
+
+func (w *bodyCapturingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *bodyCapturingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.status = http.StatusOK
+		w.wroteHeader = true
+	}
+	capBuf(&w.buf, b, w.limit)
+	return w.ResponseWriter.Write(b)
+}
+
+// capBuf appends as much of b into buf as fits under limit.
+func capBuf(buf *bytes.Buffer, b []byte, limit int64) {
+	remaining := limit - int64(buf.Len())
+	if remaining <= 0 {
+		return
+	}
+	if int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+	buf.Write(b)
+}
+
+// redactHeaders copies h, replacing the value of every header whose name
+// appears in denylist (case-insensitive) with "REDACTED".
+func redactHeaders(h http.Header, denylist []string) map[string][]string {
+	deny := make(map[string]bool, len(denylist))
+	for _, name := range denylist {
+		deny[http.CanonicalHeaderKey(name)] = true
+	}
+
+	out := make(map[string][]string, len(h))
+	for name, values := range h {
+		if deny[http.CanonicalHeaderKey(name)] {
+			out[name] = []string{"REDACTED"}
+			continue
+		}
+		out[name] = values
+	}
+	return out
+}
+
+// requestClientIP returns the best-effort real client IP, preferring
+// X-Forwarded-For over RemoteAddr.
+func requestClientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		parts := strings.Split(xff, ",")
+		return strings.TrimSpace(parts[0])
+	}
+	return r.RemoteAddr
+}
+
+// buildCurlReproducer renders a curl command an operator can paste to
+// replay r, with denylisted headers redacted and body the teed request
+// body bytes.
+func buildCurlReproducer(r *http.Request, body []byte, denylist []string) string {
+	deny := make(map[string]bool, len(denylist))
+	for _, name := range denylist {
+		deny[http.CanonicalHeaderKey(name)] = true
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "curl -X %s", r.Method)
+	for name, values := range r.Header {
+		if deny[http.CanonicalHeaderKey(name)] {
+			fmt.Fprintf(&b, " -H %q", name+": REDACTED")
+			continue
+		}
+		for _, v := range values {
+			fmt.Fprintf(&b, " -H %q", name+": "+v)
+		}
+	}
+	if len(body) > 0 {
+		fmt.Fprintf(&b, " --data-raw %q", string(body))
+	}
+	fmt.Fprintf(&b, " %q", scheme+"://"+r.Host+r.URL.RequestURI())
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package monitoring
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestTracingMiddlewareRedactsHeadersAndCapsBody(t *testing.T) {
+	logger, err := NewLogger("test", "dev", LogConfig{Level: DebugLevel, Output: "stdout"})
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	cfg := ReproducerConfig{MaxBodyBytes: 4, HeaderDenylist: []string{"Authorization"}}
+	mw := RequestTracingMiddleware(logger, cfg)
+
+	var captured *http.Request
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, 1024)
+		n, _ := r.Body.Read(body)
+		captured = r
+		_ = n
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("responsebody"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/licenses/verify", strings.NewReader("0123456789"))
+	req.Header.Set("Authorization", "Bearer secret-token")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if captured == nil {
+		t.Fatal("handler was not invoked")
+	}
+	if got := captured.Body.(*boundedTeeReadCloser).buf.String(); got != "0123" {
+		t.Fatalf("captured request body = %q, want %q", got, "0123")
+	}
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+
+	redacted := redactHeaders(http.Header{"Authorization": []string{"Bearer secret-token"}}, cfg.HeaderDenylist)
+	if redacted["Authorization"][0] != "REDACTED" {
+		t.Fatalf("Authorization header was not redacted: %v", redacted)
+	}
+
+	curl := buildCurlReproducer(req, []byte("0123456789"), cfg.HeaderDenylist)
+	if strings.Contains(curl, "secret-token") {
+		t.Fatalf("curl reproducer leaked the Authorization header: %s", curl)
+	}
+}
+
+func TestCapBufRespectsLimit(t *testing.T) {
+	var buf bytes.Buffer
+	capBuf(&buf, []byte("hello world"), 5)
+	if got := buf.String(); got != "hello" {
+		t.Fatalf("capBuf = %q, want %q", got, "hello")
+	}
+}
@@ -0,0 +1,67 @@
+package monitoring
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// statusCapturingWriter wraps an http.ResponseWriter to capture the status
+// code written, defaulting to 200 since a handler that never calls
+// WriteHeader sends an implicit 200 OK.
+type statusCapturingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusCapturingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusCapturingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.status = http.StatusOK
+		w.wroteHeader = true
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// HTTPMiddleware returns chi middleware that records HTTPRequestsInFlight,
+// HTTPRequestsTotal, and HTTPRequestDuration for every request. The endpoint
+// label is the matched chi route pattern (e.g. "/api/licenses/{id}") rather
+// than the raw path, so path parameters don't explode series cardinality.
+func HTTPMiddleware(m *Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.HTTPRequestsInFlight.Inc()
+			defer m.HTTPRequestsInFlight.Dec()
+
+			sw := &statusCapturingWriter{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(sw, r)
+			duration := time.Since(start)
+
+			endpoint := routePattern(r)
+			m.RecordHTTPRequest(r.Method, endpoint, strconv.Itoa(sw.status), duration)
+		})
+	}
+}
+
+// routePattern returns the chi route pattern matched for r, falling back to
+// the raw URL path if the request never reached chi's router (e.g. a 404
+// for a path with no matching route).
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
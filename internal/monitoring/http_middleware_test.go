@@ -0,0 +1,58 @@
+package monitoring
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestHTTPMiddlewareUsesRoutePatternAsEndpointLabel(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	r := chi.NewRouter()
+	r.Use(HTTPMiddleware(m))
+	r.Get("/licenses/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/licenses/abc-123", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+
+	var found bool
+	for _, f := range families {
+		if f.GetName() != "http_requests_total" {
+			continue
+		}
+		for _, metric := range f.Metric {
+			if labelValue(metric, "endpoint") == "/licenses/{id}" {
+				found = true
+			}
+			if labelValue(metric, "endpoint") == "/licenses/abc-123" {
+				t.Fatalf("endpoint label leaked the raw path instead of the route pattern: %+v", metric)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a http_requests_total series labeled endpoint=/licenses/{id}, families: %+v", families)
+	}
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, l := range m.Label {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,291 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultExportBufferSize is the ring buffer capacity used when
+// ExportConfig.BufferSize is unset.
+const defaultExportBufferSize = 1000
+
+// defaultExportBatchSize is the max number of entries shipped per flush
+// when ExportConfig.BatchSize is unset.
+const defaultExportBatchSize = 100
+
+// defaultExportFlushInterval is how often the pipeline flushes when
+// ExportConfig.FlushInterval is unset.
+const defaultExportFlushInterval = 5 * time.Second
+
+// LogTransport ships a batch of LogEntry to a remote log sink (Loki,
+// Elasticsearch, OTLP, or a caller-supplied destination). Implementations
+// should themselves retry transient failures; Send returning an error
+// simply drops that batch rather than retrying it indefinitely, so a
+// persistent outage can't grow the ring buffer without bound.
+type LogTransport interface {
+	Send(ctx context.Context, entries []LogEntry) error
+}
+
+// ExportConfig enables shipping every log entry written through
+// monitoring.Logger to a remote sink, in addition to (not instead of) the
+// LogConfig stdout/file output. Writes go through a bounded, non-blocking
+// ring buffer: a transport outage causes the oldest buffered entries to be
+// dropped (and DroppedCounter incremented) rather than blocking the
+// application's log calls.
+type ExportConfig struct {
+	Enabled bool
+
+	// Transport is where batches are shipped. Use NewLokiTransport,
+	// NewElasticsearchTransport or NewOTLPTransport, or supply any type
+	// implementing LogTransport to add a destination without touching
+	// this package.
+	Transport LogTransport
+
+	// BufferSize is the ring buffer capacity; defaults to 1000 entries.
+	BufferSize int
+	// BatchSize is the max entries shipped per flush; defaults to 100.
+	BatchSize int
+	// FlushInterval is how often buffered entries are flushed; defaults
+	// to 5s.
+	FlushInterval time.Duration
+
+	// DroppedCounter, if set, is incremented once per entry the ring
+	// buffer evicts to make room for a newer one.
+	DroppedCounter prometheus.Counter
+}
+
+func (c ExportConfig) withDefaults() ExportConfig {
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultExportBufferSize
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultExportBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaultExportFlushInterval
+	}
+	return c
+}
+
+// ringBuffer is a bounded FIFO of LogEntry. push never blocks: once full,
+// the oldest entry is evicted to make room for the newest.
+type ringBuffer struct {
+	mu       sync.Mutex
+	entries  []LogEntry
+	capacity int
+	dropped  prometheus.Counter
+}
+
+func newRingBuffer(capacity int, dropped prometheus.Counter) *ringBuffer {
+	return &ringBuffer{entries: make([]LogEntry, 0, capacity), capacity: capacity, dropped: dropped}
+}
+
+func (b *ringBuffer) push(entry LogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) >= b.capacity {
+		b.entries = b.entries[1:]
+		if b.dropped != nil {
+			b.dropped.Inc()
+		}
+	}
+	b.entries = append(b.entries, entry)
+}
+
+// drain removes and returns up to max entries from the front of the
+// buffer.
+func (b *ringBuffer) drain(max int) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if max <= 0 || max > len(b.entries) {
+		max = len(b.entries)
+	}
+	out := append([]LogEntry(nil), b.entries[:max]...)
+	b.entries = b.entries[max:]
+	return out
+}
+
+// exportPipeline owns a ringBuffer and periodically ships its contents to
+// a LogTransport on a background goroutine.
+type exportPipeline struct {
+	buf       *ringBuffer
+	transport LogTransport
+	batchSize int
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+func newExportPipeline(cfg ExportConfig) *exportPipeline {
+	cfg = cfg.withDefaults()
+	p := &exportPipeline{
+		buf:       newRingBuffer(cfg.BufferSize, cfg.DroppedCounter),
+		transport: cfg.Transport,
+		batchSize: cfg.BatchSize,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+	go p.run(cfg.FlushInterval)
+	return p
+}
+
+func (p *exportPipeline) run(interval time.Duration) {
+	defer close(p.doneCh)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush()
+		case <-p.stopCh:
+			p.flush()
+			return
+		}
+	}
+}
+
+// flush drains and ships full batches until the buffer runs dry or the
+// transport fails; on failure the failed batch is dropped rather than
+// requeued, since the transport is expected to own its own retries.
+func (p *exportPipeline) flush() {
+	for {
+		batch := p.buf.drain(p.batchSize)
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.transport.Send(context.Background(), batch); err != nil {
+			return
+		}
+		if len(batch) < p.batchSize {
+			return
+		}
+	}
+}
+
+// Close stops the background flusher after a final flush.
+func (p *exportPipeline) Close() error {
+	close(p.stopCh)
+	<-p.doneCh
+	return nil
+}
+
+// exportCore is a zapcore.Core that converts every accepted entry to a
+// LogEntry and pushes it onto an exportPipeline's ring buffer. It is
+// combined with the stdout/file core via zapcore.NewTee so remote
+// shipping never blocks or interferes with local logging.
+type exportCore struct {
+	zapcore.LevelEnabler
+	fields   []zapcore.Field
+	pipeline *exportPipeline
+}
+
+func newExportCore(enab zapcore.LevelEnabler, pipeline *exportPipeline) *exportCore {
+	return &exportCore{LevelEnabler: enab, pipeline: pipeline}
+}
+
+func (c *exportCore) With(fields []zapcore.Field) zapcore.Core {
+	return &exportCore{
+		LevelEnabler: c.LevelEnabler,
+		fields:       append(append([]zapcore.Field{}, c.fields...), fields...),
+		pipeline:     c.pipeline,
+	}
+}
+
+func (c *exportCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *exportCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	c.pipeline.buf.push(zapEntryToLogEntry(ent, all))
+	return nil
+}
+
+func (c *exportCore) Sync() error {
+	c.pipeline.flush()
+	return nil
+}
+
+// zapEntryToLogEntry maps a zap entry plus its accumulated fields onto the
+// LogEntry schema shared by WriteLogEntry/GetLogEntry, so every transport
+// ships a uniform shape regardless of which Log* helper produced it.
+func zapEntryToLogEntry(ent zapcore.Entry, fields []zapcore.Field) LogEntry {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	entry := LogEntry{
+		Timestamp: ent.Time,
+		Level:     ent.Level.String(),
+		Message:   ent.Message,
+	}
+
+	details := make(map[string]interface{}, len(enc.Fields))
+	for key, value := range enc.Fields {
+		switch key {
+		case "service":
+			entry.Service, _ = value.(string)
+		case "version":
+			entry.Version, _ = value.(string)
+		case "trace_id":
+			entry.TraceID, _ = value.(string)
+		case "user_id":
+			entry.UserID, _ = value.(string)
+		case "error":
+			entry.Error = stringifyFieldValue(value)
+		case "http_method":
+			entry.HTTPMethod, _ = value.(string)
+		case "http_path":
+			entry.HTTPPath, _ = value.(string)
+		case "http_status":
+			entry.HTTPStatus = intFieldValue(value)
+		case "user_agent":
+			entry.UserAgent, _ = value.(string)
+		case "remote_addr":
+			entry.RemoteAddr, _ = value.(string)
+		default:
+			details[key] = value
+		}
+	}
+	if ent.Stack != "" {
+		entry.Stack = ent.Stack
+	}
+	if len(details) > 0 {
+		entry.Fields = details
+	}
+	return entry
+}
+
+func stringifyFieldValue(v interface{}) string {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func intFieldValue(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
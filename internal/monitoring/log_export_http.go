@@ -0,0 +1,96 @@
+package monitoring
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultTransportTimeout bounds a single HTTP attempt made by the
+// built-in log transports.
+const defaultTransportTimeout = 10 * time.Second
+
+// newTransportHTTPClient builds the *http.Client shared by the built-in
+// transports, or client if it's non-nil (letting callers reuse a
+// connection-pooled client across transports).
+func newTransportHTTPClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: defaultTransportTimeout}
+}
+
+// gzipPayload compresses data, since every built-in transport's push
+// endpoint accepts (and the SIEMs/log backends they target strongly
+// prefer) gzip-encoded bodies for batches that can run into the hundreds
+// of KB.
+func gzipPayload(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return nil, fmt.Errorf("gzip write failed: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("gzip close failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// postGzipJSON sends body (already JSON/NDJSON-encoded) gzip-compressed
+// to url with contentType, retrying with exponential backoff on a 5xx
+// response or a transport-level error. A non-5xx error status fails fast
+// without retrying, since retrying a 4xx would just repeat the same
+// rejection.
+func postGzipJSON(ctx context.Context, client *http.Client, url, contentType string, body []byte, headers map[string]string) error {
+	compressed, err := gzipPayload(body)
+	if err != nil {
+		return err
+	}
+
+	const maxAttempts = 3
+	backoff := 200 * time.Millisecond
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(compressed))
+		if err != nil {
+			return fmt.Errorf("request build failed: %w", err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Content-Encoding", "gzip")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("request failed: %w", err)
+		} else {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				if resp.StatusCode >= 300 {
+					return fmt.Errorf("request returned status %d", resp.StatusCode)
+				}
+				return nil
+			}
+			lastErr = fmt.Errorf("request returned status %d", resp.StatusCode)
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+
+	return lastErr
+}
@@ -0,0 +1,97 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeTransport records every batch it's sent, optionally failing the
+// first N calls to exercise the drop-on-failure path.
+type fakeTransport struct {
+	mu      sync.Mutex
+	batches [][]LogEntry
+	failN   int
+}
+
+func (f *fakeTransport) Send(_ context.Context, entries []LogEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failN > 0 {
+		f.failN--
+		return context.DeadlineExceeded
+	}
+	f.batches = append(f.batches, entries)
+	return nil
+}
+
+func (f *fakeTransport) sent() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, b := range f.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestRingBufferDropsOldestOnOverflow(t *testing.T) {
+	buf := newRingBuffer(2, nil)
+	buf.push(LogEntry{Message: "one"})
+	buf.push(LogEntry{Message: "two"})
+	buf.push(LogEntry{Message: "three"})
+
+	got := buf.drain(10)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries after overflow, got %d", len(got))
+	}
+	if got[0].Message != "two" || got[1].Message != "three" {
+		t.Fatalf("expected the oldest entry to be dropped, got %+v", got)
+	}
+}
+
+func TestExportPipelineFlushesOnClose(t *testing.T) {
+	transport := &fakeTransport{}
+	pipeline := newExportPipeline(ExportConfig{
+		Transport:     transport,
+		BufferSize:    10,
+		BatchSize:     10,
+		FlushInterval: time.Hour, // rely on Close's final flush, not the ticker
+	})
+
+	pipeline.buf.push(LogEntry{Message: "a"})
+	pipeline.buf.push(LogEntry{Message: "b"})
+
+	if err := pipeline.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if n := transport.sent(); n != 2 {
+		t.Fatalf("expected 2 entries shipped on close, got %d", n)
+	}
+}
+
+func TestExportCoreWriteConvertsFieldsToLogEntry(t *testing.T) {
+	transport := &fakeTransport{}
+	pipeline := newExportPipeline(ExportConfig{Transport: transport, BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour})
+	defer pipeline.Close()
+
+	core := newExportCore(zapcore.InfoLevel, pipeline)
+	core = core.With([]zapcore.Field{zapcore.Field{Key: "service", Type: zapcore.StringType, String: "slicense"}}).(*exportCore)
+
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "license issued", Time: time.Now()}
+	if err := core.Write(ent, []zapcore.Field{zapcore.Field{Key: "user_id", Type: zapcore.StringType, String: "42"}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	entries := pipeline.buf.drain(10)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 buffered entry, got %d", len(entries))
+	}
+	got := entries[0]
+	if got.Service != "slicense" || got.UserID != "42" || got.Message != "license issued" {
+		t.Fatalf("unexpected entry: %+v", got)
+	}
+}
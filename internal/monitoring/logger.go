@@ -6,17 +6,22 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"license-server/internal/audit"
 )
 
 // Logger wraps zap.Logger with additional context
 type Logger struct {
 	*zap.Logger
-	service string
-	version string
+	service    string
+	version    string
+	auditSink  audit.Sink
+	exportPipe *exportPipeline
 }
 
 // LogLevel represents the logging level
@@ -43,26 +48,31 @@ type LogConfig struct {
 	Format   LogFormat `yaml:"format"`
 	Output   string    `yaml:"output"` // stdout, file, both
 	FilePath string    `yaml:"file_path"`
+
+	// Export, when Enabled, tees every entry to a remote log sink (Loki,
+	// Elasticsearch, OTLP, or a custom LogTransport) in addition to the
+	// Output above.
+	Export ExportConfig
 }
 
 // LogEntry represents a structured log entry
 type LogEntry struct {
-	Timestamp   time.Time              `json:"timestamp"`
-	Level       string                 `json:"level"`
-	Service     string                 `json:"service"`
-	Version     string                 `json:"version"`
-	TraceID     string                 `json:"trace_id,omitempty"`
-	UserID      string                 `json:"user_id,omitempty"`
-	Message     string                 `json:"message"`
-	Fields      map[string]interface{} `json:"fields,omitempty"`
-	Error       string                 `json:"error,omitempty"`
-	Stack       string                 `json:"stack,omitempty"`
-	Duration    int64                  `json:"duration_ms,omitempty"`
-	HTTPMethod  string                 `json:"http_method,omitempty"`
-	HTTPPath    string                 `json:"http_path,omitempty"`
-	HTTPStatus  int                    `json:"http_status,omitempty"`
-	UserAgent   string                 `json:"user_agent,omitempty"`
-	RemoteAddr  string                 `json:"remote_addr,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Level      string                 `json:"level"`
+	Service    string                 `json:"service"`
+	Version    string                 `json:"version"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	UserID     string                 `json:"user_id,omitempty"`
+	Message    string                 `json:"message"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Stack      string                 `json:"stack,omitempty"`
+	Duration   int64                  `json:"duration_ms,omitempty"`
+	HTTPMethod string                 `json:"http_method,omitempty"`
+	HTTPPath   string                 `json:"http_path,omitempty"`
+	HTTPStatus int                    `json:"http_status,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	RemoteAddr string                 `json:"remote_addr,omitempty"`
 }
 
 // NewLogger creates a new structured logger
@@ -145,6 +155,15 @@ func NewLogger(service, version string, config LogConfig) (*Logger, error) {
 	// Create core
 	core := zapcore.NewCore(encoder, writeSyncer, zapLevel)
 
+	var pipeline *exportPipeline
+	if config.Export.Enabled {
+		if config.Export.Transport == nil {
+			return nil, fmt.Errorf("log export enabled without a Transport")
+		}
+		pipeline = newExportPipeline(config.Export)
+		core = zapcore.NewTee(core, newExportCore(zapLevel, pipeline))
+	}
+
 	// Create logger with service and version fields
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 	logger = logger.With(
@@ -153,9 +172,10 @@ func NewLogger(service, version string, config LogConfig) (*Logger, error) {
 	)
 
 	return &Logger{
-		Logger:  logger,
-		service: service,
-		version: version,
+		Logger:     logger,
+		service:    service,
+		version:    version,
+		exportPipe: pipeline,
 	}, nil
 }
 
@@ -164,9 +184,11 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	// Extract trace ID from context if available
 	if traceID := ctx.Value("trace_id"); traceID != nil {
 		return &Logger{
-			Logger:  l.Logger.With(zap.String("trace_id", traceID.(string))),
-			service: l.service,
-			version: l.version,
+			Logger:     l.Logger.With(zap.String("trace_id", traceID.(string))),
+			service:    l.service,
+			version:    l.version,
+			auditSink:  l.auditSink,
+			exportPipe: l.exportPipe,
 		}
 	}
 	return l
@@ -175,9 +197,11 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 // WithUser adds user context to the logger
 func (l *Logger) WithUser(userID string) *Logger {
 	return &Logger{
-		Logger:  l.Logger.With(zap.String("user_id", userID)),
-		service: l.service,
-		version: l.version,
+		Logger:     l.Logger.With(zap.String("user_id", userID)),
+		service:    l.service,
+		version:    l.version,
+		auditSink:  l.auditSink,
+		exportPipe: l.exportPipe,
 	}
 }
 
@@ -188,15 +212,65 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 		zapFields = append(zapFields, zap.Any(key, value))
 	}
 	return &Logger{
-		Logger:  l.Logger.With(zapFields...),
-		service: l.service,
-		version: l.version,
+		Logger:     l.Logger.With(zapFields...),
+		service:    l.service,
+		version:    l.version,
+		auditSink:  l.auditSink,
+		exportPipe: l.exportPipe,
+	}
+}
+
+// WithAuditSink attaches an audit.Sink that LogAudit persists every audit
+// event to (in addition to its usual zap line). A write failure on the
+// sink is itself logged via zap rather than returned, since LogAudit's
+// signature predates the sink and callers don't check its return value.
+func (l *Logger) WithAuditSink(sink audit.Sink) *Logger {
+	return &Logger{
+		Logger:     l.Logger,
+		service:    l.service,
+		version:    l.version,
+		auditSink:  sink,
+		exportPipe: l.exportPipe,
+	}
+}
+
+// fieldSlicePools holds pooled []zap.Field slices for the map-based Log*
+// helpers below, bucketed by expected size so a call with a handful of
+// fields doesn't grab (and reset) a slice sized for a much larger one.
+// Fixed-arity helpers (LogHTTPRequest, LogDatabaseOperation, ...) don't
+// need pooling since their field list is a small, stack-allocatable
+// literal already guarded by Check.
+var (
+	smallFieldPool  = sync.Pool{New: func() any { s := make([]zap.Field, 0, 8); return &s }}
+	mediumFieldPool = sync.Pool{New: func() any { s := make([]zap.Field, 0, 32); return &s }}
+	largeFieldPool  = sync.Pool{New: func() any { s := make([]zap.Field, 0, 128); return &s }}
+)
+
+// getFieldSlice returns a zeroed []zap.Field with spare capacity for about
+// expectedLen fields, plus the pool to return it to via putFieldSlice.
+func getFieldSlice(expectedLen int) (*[]zap.Field, *sync.Pool) {
+	switch {
+	case expectedLen <= 8:
+		return smallFieldPool.Get().(*[]zap.Field), &smallFieldPool
+	case expectedLen <= 32:
+		return mediumFieldPool.Get().(*[]zap.Field), &mediumFieldPool
+	default:
+		return largeFieldPool.Get().(*[]zap.Field), &largeFieldPool
 	}
 }
 
+func putFieldSlice(s *[]zap.Field, pool *sync.Pool) {
+	*s = (*s)[:0]
+	pool.Put(s)
+}
+
 // LogHTTPRequest logs HTTP request details
 func (l *Logger) LogHTTPRequest(method, path string, statusCode int, duration time.Duration, userAgent, remoteAddr string) {
-	l.Info("HTTP request completed",
+	ce := l.Logger.Check(zapcore.InfoLevel, "HTTP request completed")
+	if ce == nil {
+		return
+	}
+	ce.Write(
 		zap.String("http_method", method),
 		zap.String("http_path", path),
 		zap.Int("http_status", statusCode),
@@ -208,7 +282,11 @@ func (l *Logger) LogHTTPRequest(method, path string, statusCode int, duration ti
 
 // LogLicenseVerification logs license verification events
 func (l *Logger) LogLicenseVerification(licenseKey, product string, valid bool, duration time.Duration) {
-	l.Info("License verification",
+	ce := l.Logger.Check(zapcore.InfoLevel, "License verification")
+	if ce == nil {
+		return
+	}
+	ce.Write(
 		zap.String("license_key", licenseKey),
 		zap.String("product", product),
 		zap.Bool("valid", valid),
@@ -218,7 +296,11 @@ func (l *Logger) LogLicenseVerification(licenseKey, product string, valid bool,
 
 // LogLicenseActivation logs license activation events
 func (l *Logger) LogLicenseActivation(licenseKey, product, machineID string, success bool) {
-	l.Info("License activation",
+	ce := l.Logger.Check(zapcore.InfoLevel, "License activation")
+	if ce == nil {
+		return
+	}
+	ce.Write(
 		zap.String("license_key", licenseKey),
 		zap.String("product", product),
 		zap.String("machine_id", machineID),
@@ -228,19 +310,30 @@ func (l *Logger) LogLicenseActivation(licenseKey, product, machineID string, suc
 
 // LogUserAction logs user actions
 func (l *Logger) LogUserAction(userID, action string, details map[string]interface{}) {
-	fields := []zap.Field{
-		zap.String("user_id", userID),
-		zap.String("action", action),
+	ce := l.Logger.Check(zapcore.InfoLevel, "User action")
+	if ce == nil {
+		return
 	}
+
+	fieldsPtr, pool := getFieldSlice(len(details) + 2)
+	defer putFieldSlice(fieldsPtr, pool)
+
+	fields := append(*fieldsPtr, zap.String("user_id", userID), zap.String("action", action))
 	for key, value := range details {
 		fields = append(fields, zap.Any(key, value))
 	}
-	l.Info("User action", fields...)
+	*fieldsPtr = fields
+
+	ce.Write(fields...)
 }
 
 // LogDatabaseOperation logs database operations
 func (l *Logger) LogDatabaseOperation(operation, table string, duration time.Duration, success bool) {
-	l.Info("Database operation",
+	ce := l.Logger.Check(zapcore.InfoLevel, "Database operation")
+	if ce == nil {
+		return
+	}
+	ce.Write(
 		zap.String("operation", operation),
 		zap.String("table", table),
 		zap.Duration("duration", duration),
@@ -250,14 +343,21 @@ func (l *Logger) LogDatabaseOperation(operation, table string, duration time.Dur
 
 // LogSecurityEvent logs security-related events
 func (l *Logger) LogSecurityEvent(eventType, severity string, details map[string]interface{}) {
-	fields := []zap.Field{
-		zap.String("event_type", eventType),
-		zap.String("severity", severity),
+	ce := l.Logger.Check(zapcore.WarnLevel, "Security event")
+	if ce == nil {
+		return
 	}
+
+	fieldsPtr, pool := getFieldSlice(len(details) + 2)
+	defer putFieldSlice(fieldsPtr, pool)
+
+	fields := append(*fieldsPtr, zap.String("event_type", eventType), zap.String("severity", severity))
 	for key, value := range details {
 		fields = append(fields, zap.Any(key, value))
 	}
-	l.Warn("Security event", fields...)
+	*fieldsPtr = fields
+
+	ce.Write(fields...)
 }
 
 // LogError logs errors with stack trace
@@ -276,7 +376,11 @@ func (l *Logger) LogPanic(recoverValue interface{}, stack []byte) {
 
 // LogPerformance logs performance metrics
 func (l *Logger) LogPerformance(operation string, duration time.Duration, memoryBefore, memoryAfter uint64) {
-	l.Info("Performance metric",
+	ce := l.Logger.Check(zapcore.InfoLevel, "Performance metric")
+	if ce == nil {
+		return
+	}
+	ce.Write(
 		zap.String("operation", operation),
 		zap.Duration("duration", duration),
 		zap.Uint64("memory_before_bytes", memoryBefore),
@@ -285,28 +389,52 @@ func (l *Logger) LogPerformance(operation string, duration time.Duration, memory
 	)
 }
 
-// LogAudit logs audit trail events
+// LogAudit logs audit trail events and, when the Logger was built with
+// WithAuditSink, persists them into the tamper-evident hash chain so
+// license-affecting actions (issue/revoke/activate), logins and admin
+// actions get a forensic trail beyond the zap log stream. The sink write
+// happens unconditionally, even if the zap line itself is below the
+// configured level, since the audit chain is a forensic record independent
+// of log verbosity.
 func (l *Logger) LogAudit(action, resource string, userID string, details map[string]interface{}) {
-	fields := []zap.Field{
-		zap.String("audit_action", action),
-		zap.String("audit_resource", resource),
-		zap.String("user_id", userID),
+	if ce := l.Logger.Check(zapcore.InfoLevel, "Audit log"); ce != nil {
+		fieldsPtr, pool := getFieldSlice(len(details) + 3)
+
+		fields := append(*fieldsPtr, zap.String("audit_action", action), zap.String("audit_resource", resource), zap.String("user_id", userID))
+		for key, value := range details {
+			fields = append(fields, zap.Any(key, value))
+		}
+		*fieldsPtr = fields
+
+		ce.Write(fields...)
+		putFieldSlice(fieldsPtr, pool)
 	}
-	for key, value := range details {
-		fields = append(fields, zap.Any(key, value))
+
+	if l.auditSink == nil {
+		return
+	}
+	if _, err := l.auditSink.Append(context.Background(), action, userID, resource, details); err != nil {
+		l.Error("Failed to persist audit event to sink", zap.Error(err), zap.String("audit_action", action))
 	}
-	l.Info("Audit log", fields...)
 }
 
 // LogBusinessEvent logs business-specific events
 func (l *Logger) LogBusinessEvent(eventType string, details map[string]interface{}) {
-	fields := []zap.Field{
-		zap.String("business_event", eventType),
+	ce := l.Logger.Check(zapcore.InfoLevel, "Business event")
+	if ce == nil {
+		return
 	}
+
+	fieldsPtr, pool := getFieldSlice(len(details) + 1)
+	defer putFieldSlice(fieldsPtr, pool)
+
+	fields := append(*fieldsPtr, zap.String("business_event", eventType))
 	for key, value := range details {
 		fields = append(fields, zap.Any(key, value))
 	}
-	l.Info("Business event", fields...)
+	*fieldsPtr = fields
+
+	ce.Write(fields...)
 }
 
 // GetLogEntry creates a structured log entry
@@ -345,7 +473,15 @@ func (l *Logger) Flush() error {
 	return l.Logger.Sync()
 }
 
-// Close closes the logger and flushes any buffered entries
+// Close closes the logger and flushes any buffered entries, including
+// stopping the export pipeline (if Export was enabled) after a final
+// flush.
 func (l *Logger) Close() error {
-	return l.Logger.Sync()
-}
\ No newline at end of file
+	err := l.Logger.Sync()
+	if l.exportPipe != nil {
+		if closeErr := l.exportPipe.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package monitoring
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// discardLogger builds a Logger writing to io.Discard at the given level,
+// bypassing NewLogger's file/stdout setup so benchmarks and alloc checks
+// never touch a real sink.
+func discardLogger(level zapcore.Level) *Logger {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	core := zapcore.NewCore(encoder, zapcore.AddSync(io.Discard), level)
+	return &Logger{Logger: zap.New(core), service: "bench", version: "test"}
+}
+
+func TestLogHelpersAllocateZeroWhenLevelDisabled(t *testing.T) {
+	logger := discardLogger(zapcore.ErrorLevel) // Info/Warn helpers below are all disabled
+
+	cases := []struct {
+		name string
+		fn   func()
+	}{
+		{"LogHTTPRequest", func() {
+			logger.LogHTTPRequest("GET", "/license/verify", 200, time.Millisecond, "curl/8", "127.0.0.1")
+		}},
+		{"LogLicenseVerification", func() {
+			logger.LogLicenseVerification("key", "product", true, time.Millisecond)
+		}},
+		{"LogUserAction", func() {
+			logger.LogUserAction("42", "login", map[string]interface{}{"ip": "127.0.0.1"})
+		}},
+		{"LogSecurityEvent", func() {
+			logger.LogSecurityEvent("rate_limit", "warn", map[string]interface{}{"ip": "127.0.0.1"})
+		}},
+		{"LogBusinessEvent", func() {
+			logger.LogBusinessEvent("license_issued", map[string]interface{}{"product": "acme"})
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, tc.fn)
+			if allocs != 0 {
+				t.Fatalf("expected 0 allocations with the level disabled, got %v", allocs)
+			}
+		})
+	}
+}
+
+// TestLogAuditAllocatesOnlyForSinkForwarding documents that LogAudit can't
+// reach zero allocations purely by disabling the zap level: it forwards
+// details to l.auditSink.Append, an audit.Sink interface call, so the
+// compiler must assume the argument escapes regardless of whether a sink
+// is attached (escape analysis can't see through the interface boundary to
+// know the nil check guards it at runtime). This is the same independent-
+// of-log-level behavior LogAudit's doc comment already calls out for the
+// sink write itself.
+func TestLogAuditAllocatesOnlyForSinkForwarding(t *testing.T) {
+	logger := discardLogger(zapcore.ErrorLevel)
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.LogAudit("issue", "license", "42", map[string]interface{}{"product": "acme"})
+	})
+	if allocs == 0 {
+		t.Fatal("expected the audit-sink forwarding argument to still escape even with logging disabled")
+	}
+}
+
+func BenchmarkLogUserActionDisabled(b *testing.B) {
+	logger := discardLogger(zapcore.ErrorLevel)
+	details := map[string]interface{}{"ip": "127.0.0.1", "reason": "ok"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.LogUserAction("42", "login", details)
+	}
+}
+
+func BenchmarkLogUserActionEnabled(b *testing.B) {
+	logger := discardLogger(zapcore.InfoLevel)
+	details := map[string]interface{}{"ip": "127.0.0.1", "reason": "ok"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.LogUserAction("42", "login", details)
+	}
+}
+
+func BenchmarkLogLicenseVerificationEnabled(b *testing.B) {
+	logger := discardLogger(zapcore.InfoLevel)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.LogLicenseVerification("key", "product", true, time.Millisecond)
+	}
+}
+
+func BenchmarkLogAuditEnabled(b *testing.B) {
+	logger := discardLogger(zapcore.InfoLevel)
+	details := map[string]interface{}{"product": "acme"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.LogAudit("issue", "license", "42", details)
+	}
+}
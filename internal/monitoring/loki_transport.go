@@ -0,0 +1,52 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// LokiTransport ships batches to a Grafana Loki /loki/api/v1/push
+// endpoint, tagging every entry with a fixed set of stream labels.
+type LokiTransport struct {
+	client  *http.Client
+	pushURL string
+	labels  map[string]string
+}
+
+// NewLokiTransport creates a LokiTransport posting to pushURL (e.g.
+// "http://loki:3100/loki/api/v1/push") with the given stream labels
+// applied to every batch.
+func NewLokiTransport(pushURL string, labels map[string]string) *LokiTransport {
+	return &LokiTransport{client: newTransportHTTPClient(nil), pushURL: pushURL, labels: labels}
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Send implements LogTransport.
+func (t *LokiTransport) Send(ctx context.Context, entries []LogEntry) error {
+	values := make([][2]string, 0, len(entries))
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("loki: entry marshal failed: %w", err)
+		}
+		values = append(values, [2]string{strconv.FormatInt(entry.Timestamp.UnixNano(), 10), string(line)})
+	}
+
+	body, err := json.Marshal(lokiPushRequest{Streams: []lokiStream{{Stream: t.labels, Values: values}}})
+	if err != nil {
+		return fmt.Errorf("loki: batch marshal failed: %w", err)
+	}
+
+	return postGzipJSON(ctx, t.client, t.pushURL, "application/json", body, nil)
+}
@@ -2,6 +2,10 @@ package monitoring
 
 import (
 	"context"
+	"database/sql"
+	"regexp"
+	"runtime"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -11,9 +15,9 @@ import (
 // Metrics holds all Prometheus metrics for the application
 type Metrics struct {
 	// HTTP metrics
-	HTTPRequestsTotal     *prometheus.CounterVec
-	HTTPRequestDuration   *prometheus.HistogramVec
-	HTTPRequestsInFlight  prometheus.Gauge
+	HTTPRequestsTotal    *prometheus.CounterVec
+	HTTPRequestDuration  *prometheus.HistogramVec
+	HTTPRequestsInFlight prometheus.Gauge
 
 	// License metrics
 	LicenseVerificationsTotal *prometheus.CounterVec
@@ -21,16 +25,22 @@ type Metrics struct {
 	LicenseCreationsTotal     *prometheus.CounterVec
 	LicenseDeletionsTotal     *prometheus.CounterVec
 
+	// productsMu guards seenProducts, the bounded set of product labels
+	// sanitizeProduct has already let through, so a caller (an
+	// unauthenticated POST /license/verify in particular) can't grow
+	// LicenseVerificationsTotal/LicenseActivationsTotal's cardinality
+	// without bound just by making up new product names.
+	productsMu   sync.Mutex
+	seenProducts map[string]struct{}
+
 	// User metrics
 	UserRegistrationsTotal *prometheus.CounterVec
 	UserLoginsTotal        *prometheus.CounterVec
 	UserLoginsFailed       *prometheus.CounterVec
 
 	// Database metrics
-	DatabaseConnectionsActive prometheus.Gauge
-	DatabaseConnectionsIdle   prometheus.Gauge
-	DatabaseQueryDuration     *prometheus.HistogramVec
-	DatabaseErrorsTotal       *prometheus.CounterVec
+	DatabaseQueryDuration *prometheus.HistogramVec
+	DatabaseErrorsTotal   *prometheus.CounterVec
 
 	// Cache metrics
 	CacheHitsTotal   *prometheus.CounterVec
@@ -38,38 +48,82 @@ type Metrics struct {
 	CacheOperations  *prometheus.CounterVec
 
 	// System metrics
-	SystemMemoryUsage    prometheus.Gauge
-	SystemCPUUsage       prometheus.Gauge
-	SystemGoroutines     prometheus.Gauge
-	SystemGCPause        *prometheus.HistogramVec
+	SystemMemoryUsage prometheus.Gauge
+	SystemCPUUsage    prometheus.Gauge
+	SystemGoroutines  prometheus.Gauge
+	SystemGCPause     *prometheus.HistogramVec
 
 	// Business metrics
-	ActiveLicensesTotal    prometheus.Gauge
-	ExpiredLicensesTotal   prometheus.Gauge
-	ActiveUsersTotal       prometheus.Gauge
-	AuditLogsTotal         prometheus.Counter
+	ActiveLicensesTotal  prometheus.Gauge
+	ExpiredLicensesTotal prometheus.Gauge
+	ActiveUsersTotal     prometheus.Gauge
+	ActiveUsersLastHour  prometheus.Gauge
+	AuditLogsTotal       prometheus.Counter
+
+	// User activity metrics
+	UserLastSeenUpdatesTotal prometheus.Counter
+
+	// Session metrics
+	SessionsActive            prometheus.Gauge
+	SessionsCreatedTotal      *prometheus.CounterVec
+	SessionsRevokedTotal      *prometheus.CounterVec
+	TokenRefreshTotal         *prometheus.CounterVec
+	SessionReuseDetectedTotal prometheus.Counter
+
+	// Rate limiting metrics
+	RateLimitDecisionsTotal *prometheus.CounterVec
+
+	// Log export metrics
+	LogExportDroppedTotal prometheus.Counter
+}
+
+// MetricsOption configures optional aspects of NewMetrics construction.
+type MetricsOption func(*metricsOptions)
+
+type metricsOptions struct {
+	httpRequestDurationBuckets []float64
 }
 
-// NewMetrics creates a new Metrics instance with all Prometheus metrics
-func NewMetrics() *Metrics {
+// WithHTTPRequestDurationBuckets overrides the default histogram buckets for
+// http_request_duration_seconds. prometheus.DefBuckets tops out at 10s and
+// has its finest resolution around 100ms-1s, which undersells both a sub-ms
+// JWT check and a multi-second admin report query; callers with that mix of
+// routes should supply their own buckets.
+func WithHTTPRequestDurationBuckets(buckets []float64) MetricsOption {
+	return func(o *metricsOptions) {
+		o.httpRequestDurationBuckets = buckets
+	}
+}
+
+// NewMetrics creates a new Metrics instance with all Prometheus metrics,
+// registering them against reg instead of the global default registry so
+// tests and the dedicated metrics server can use an isolated registry.
+func NewMetrics(reg prometheus.Registerer, opts ...MetricsOption) *Metrics {
+	options := metricsOptions{httpRequestDurationBuckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	factory := promauto.With(reg)
 	return &Metrics{
+		seenProducts: make(map[string]struct{}),
 		// HTTP metrics
-		HTTPRequestsTotal: promauto.NewCounterVec(
+		HTTPRequestsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "http_requests_total",
 				Help: "Total number of HTTP requests",
 			},
 			[]string{"method", "endpoint", "status_code"},
 		),
-		HTTPRequestDuration: promauto.NewHistogramVec(
+		HTTPRequestDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "http_request_duration_seconds",
 				Help:    "HTTP request duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Buckets: options.httpRequestDurationBuckets,
 			},
 			[]string{"method", "endpoint"},
 		),
-		HTTPRequestsInFlight: promauto.NewGauge(
+		HTTPRequestsInFlight: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "http_requests_in_flight",
 				Help: "Current number of HTTP requests being processed",
@@ -77,28 +131,28 @@ func NewMetrics() *Metrics {
 		),
 
 		// License metrics
-		LicenseVerificationsTotal: promauto.NewCounterVec(
+		LicenseVerificationsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "license_verifications_total",
 				Help: "Total number of license verifications",
 			},
-			[]string{"product", "result"},
+			[]string{"product", "reason", "origin", "client_version"},
 		),
-		LicenseActivationsTotal: promauto.NewCounterVec(
+		LicenseActivationsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "license_activations_total",
 				Help: "Total number of license activations",
 			},
-			[]string{"product", "result"},
+			[]string{"product", "reason", "origin", "client_version"},
 		),
-		LicenseCreationsTotal: promauto.NewCounterVec(
+		LicenseCreationsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "license_creations_total",
 				Help: "Total number of license creations",
 			},
 			[]string{"product"},
 		),
-		LicenseDeletionsTotal: promauto.NewCounterVec(
+		LicenseDeletionsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "license_deletions_total",
 				Help: "Total number of license deletions",
@@ -107,21 +161,21 @@ func NewMetrics() *Metrics {
 		),
 
 		// User metrics
-		UserRegistrationsTotal: promauto.NewCounterVec(
+		UserRegistrationsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "user_registrations_total",
 				Help: "Total number of user registrations",
 			},
 			[]string{"result"},
 		),
-		UserLoginsTotal: promauto.NewCounterVec(
+		UserLoginsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "user_logins_total",
 				Help: "Total number of user logins",
 			},
 			[]string{"result"},
 		),
-		UserLoginsFailed: promauto.NewCounterVec(
+		UserLoginsFailed: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "user_logins_failed_total",
 				Help: "Total number of failed user logins",
@@ -130,19 +184,7 @@ func NewMetrics() *Metrics {
 		),
 
 		// Database metrics
-		DatabaseConnectionsActive: promauto.NewGauge(
-			prometheus.GaugeOpts{
-				Name: "database_connections_active",
-				Help: "Number of active database connections",
-			},
-		),
-		DatabaseConnectionsIdle: promauto.NewGauge(
-			prometheus.GaugeOpts{
-				Name: "database_connections_idle",
-				Help: "Number of idle database connections",
-			},
-		),
-		DatabaseQueryDuration: promauto.NewHistogramVec(
+		DatabaseQueryDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "database_query_duration_seconds",
 				Help:    "Database query duration in seconds",
@@ -150,7 +192,7 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"operation", "table"},
 		),
-		DatabaseErrorsTotal: promauto.NewCounterVec(
+		DatabaseErrorsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "database_errors_total",
 				Help: "Total number of database errors",
@@ -159,21 +201,21 @@ func NewMetrics() *Metrics {
 		),
 
 		// Cache metrics
-		CacheHitsTotal: promauto.NewCounterVec(
+		CacheHitsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "cache_hits_total",
 				Help: "Total number of cache hits",
 			},
 			[]string{"cache_type", "key_pattern"},
 		),
-		CacheMissesTotal: promauto.NewCounterVec(
+		CacheMissesTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "cache_misses_total",
 				Help: "Total number of cache misses",
 			},
 			[]string{"cache_type", "key_pattern"},
 		),
-		CacheOperations: promauto.NewCounterVec(
+		CacheOperations: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "cache_operations_total",
 				Help: "Total number of cache operations",
@@ -182,25 +224,25 @@ func NewMetrics() *Metrics {
 		),
 
 		// System metrics
-		SystemMemoryUsage: promauto.NewGauge(
+		SystemMemoryUsage: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "system_memory_usage_bytes",
 				Help: "Current memory usage in bytes",
 			},
 		),
-		SystemCPUUsage: promauto.NewGauge(
+		SystemCPUUsage: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "system_cpu_usage_percent",
 				Help: "Current CPU usage percentage",
 			},
 		),
-		SystemGoroutines: promauto.NewGauge(
+		SystemGoroutines: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "system_goroutines_total",
 				Help: "Current number of goroutines",
 			},
 		),
-		SystemGCPause: promauto.NewHistogramVec(
+		SystemGCPause: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "system_gc_pause_seconds",
 				Help:    "GC pause duration in seconds",
@@ -210,47 +252,202 @@ func NewMetrics() *Metrics {
 		),
 
 		// Business metrics
-		ActiveLicensesTotal: promauto.NewGauge(
+		ActiveLicensesTotal: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "active_licenses_total",
 				Help: "Total number of active licenses",
 			},
 		),
-		ExpiredLicensesTotal: promauto.NewGauge(
+		ExpiredLicensesTotal: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "expired_licenses_total",
 				Help: "Total number of expired licenses",
 			},
 		),
-		ActiveUsersTotal: promauto.NewGauge(
+		ActiveUsersTotal: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "active_users_total",
-				Help: "Total number of active users",
+				Help: "Total number of active users (30-day window)",
 			},
 		),
-		AuditLogsTotal: promauto.NewCounter(
+		ActiveUsersLastHour: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "active_users_last_hour",
+				Help: "Number of users seen in the last hour",
+			},
+		),
+		AuditLogsTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "audit_logs_total",
 				Help: "Total number of audit log entries",
 			},
 		),
+
+		UserLastSeenUpdatesTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "user_last_seen_updates_total",
+				Help: "Total number of debounced last_login touches applied by the activity middleware",
+			},
+		),
+
+		// Session metrics
+		SessionsActive: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "sessions_active",
+				Help: "Current number of non-revoked, non-expired sessions",
+			},
+		),
+		SessionsCreatedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "sessions_created_total",
+				Help: "Total number of sessions created, by result",
+			},
+			[]string{"result"},
+		),
+		SessionsRevokedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "sessions_revoked_total",
+				Help: "Total number of sessions revoked, by reason",
+			},
+			[]string{"reason"},
+		),
+		TokenRefreshTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "token_refresh_total",
+				Help: "Total number of refresh token exchanges, by result",
+			},
+			[]string{"result"},
+		),
+		SessionReuseDetectedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "session_reuse_detected_total",
+				Help: "Total number of revoked refresh tokens presented again, indicating possible token theft",
+			},
+		),
+
+		RateLimitDecisionsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rate_limit_decisions_total",
+				Help: "Total number of rate limit decisions, by matched policy and outcome",
+			},
+			[]string{"route", "decision"},
+		),
+
+		LogExportDroppedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "log_export_dropped_total",
+				Help: "Total number of log entries dropped from the remote log export ring buffer due to overflow",
+			},
+		),
 	}
 }
 
+// RegisterDBStatsCollector registers a DBStatsCollector for db against reg,
+// giving operators accurate connection pool telemetry driven by scrape
+// cadence instead of the ticker-based UpdateBusinessMetrics path.
+func RegisterDBStatsCollector(reg prometheus.Registerer, db *sql.DB) error {
+	return reg.Register(NewDBStatsCollector(db))
+}
+
 // RecordHTTPRequest records HTTP request metrics
 func (m *Metrics) RecordHTTPRequest(method, endpoint, statusCode string, duration time.Duration) {
 	m.HTTPRequestsTotal.WithLabelValues(method, endpoint, statusCode).Inc()
 	m.HTTPRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
 }
 
-// RecordLicenseVerification records license verification metrics
-func (m *Metrics) RecordLicenseVerification(product, result string) {
-	m.LicenseVerificationsTotal.WithLabelValues(product, result).Inc()
+// VerificationOutcome is the structured result of a license verification or
+// activation attempt, modeled on crowdsec's decision metric (reason/origin
+// labels) so dashboards can break volume down by why a check failed and
+// which client surfaced it.
+type VerificationOutcome struct {
+	// Reason is one of: ok, expired, hwid_mismatch, not_found, rate_limited,
+	// revoked, forged. Anything else is bucketed as "other".
+	Reason string
+	// Origin is one of: api, sdk, cli. Anything else is bucketed as "other".
+	Origin string
+	// ClientVersion is a caller-supplied version string, e.g. "1.4.2".
+	// Anything that isn't a plain dotted version number is bucketed as
+	// "unknown".
+	ClientVersion string
 }
 
-// RecordLicenseActivation records license activation metrics
-func (m *Metrics) RecordLicenseActivation(product, result string) {
-	m.LicenseActivationsTotal.WithLabelValues(product, result).Inc()
+var (
+	allowedVerificationReasons = map[string]bool{
+		"ok": true, "expired": true, "hwid_mismatch": true,
+		"not_found": true, "rate_limited": true, "revoked": true, "forged": true,
+	}
+	allowedVerificationOrigins = map[string]bool{
+		"api": true, "sdk": true, "cli": true,
+	}
+	clientVersionPattern = regexp.MustCompile(`^\d{1,4}(\.\d{1,4}){0,2}$`)
+)
+
+const (
+	otherLabel           = "other"
+	unknownClientVersion = "unknown"
+)
+
+// maxTrackedProducts bounds how many distinct product label values
+// sanitizeProduct will let through before bucketing everything else as
+// "other". No real deployment runs more than a handful of products, so
+// this is generous headroom rather than a tight whitelist.
+const maxTrackedProducts = 200
+
+// sanitizeProduct bounds the product label the same way sanitize bounds
+// reason/origin/client_version, just without a fixed whitelist: product
+// names aren't known ahead of time, so the first maxTrackedProducts
+// distinct values seen are let through verbatim and anything past that
+// is bucketed as "other" — capping the cardinality an unauthenticated
+// caller (POST /license/verify's request body) can add to the counter
+// vector regardless of how many distinct product strings it sends.
+func (m *Metrics) sanitizeProduct(product string) string {
+	m.productsMu.Lock()
+	defer m.productsMu.Unlock()
+
+	if _, ok := m.seenProducts[product]; ok {
+		return product
+	}
+	if len(m.seenProducts) >= maxTrackedProducts {
+		return otherLabel
+	}
+	m.seenProducts[product] = struct{}{}
+	return product
+}
+
+// sanitize clamps every field to its whitelist so caller-controlled input
+// (a forged origin, a made-up reason, an arbitrary version string) can't
+// blow up the cardinality of the underlying counter vector.
+func (o VerificationOutcome) sanitize() VerificationOutcome {
+	reason := o.Reason
+	if !allowedVerificationReasons[reason] {
+		reason = otherLabel
+	}
+
+	origin := o.Origin
+	if !allowedVerificationOrigins[origin] {
+		origin = otherLabel
+	}
+
+	version := o.ClientVersion
+	if !clientVersionPattern.MatchString(version) {
+		version = unknownClientVersion
+	}
+
+	return VerificationOutcome{Reason: reason, Origin: origin, ClientVersion: version}
+}
+
+// RecordLicenseVerification records a license verification outcome, with
+// reason/origin/client_version bucketed to a bounded label set.
+func (m *Metrics) RecordLicenseVerification(product string, outcome VerificationOutcome) {
+	o := outcome.sanitize()
+	m.LicenseVerificationsTotal.WithLabelValues(m.sanitizeProduct(product), o.Reason, o.Origin, o.ClientVersion).Inc()
+}
+
+// RecordLicenseActivation records a license activation outcome, with
+// reason/origin/client_version bucketed to a bounded label set.
+func (m *Metrics) RecordLicenseActivation(product string, outcome VerificationOutcome) {
+	o := outcome.sanitize()
+	m.LicenseActivationsTotal.WithLabelValues(m.sanitizeProduct(product), o.Reason, o.Origin, o.ClientVersion).Inc()
 }
 
 // RecordLicenseCreation records license creation metrics
@@ -278,6 +475,32 @@ func (m *Metrics) RecordUserLoginFailed(reason string) {
 	m.UserLoginsFailed.WithLabelValues(reason).Inc()
 }
 
+// RecordSessionCreated records a session creation attempt
+func (m *Metrics) RecordSessionCreated(result string) {
+	m.SessionsCreatedTotal.WithLabelValues(result).Inc()
+}
+
+// RecordSessionRevoked records a session revocation
+func (m *Metrics) RecordSessionRevoked(reason string) {
+	m.SessionsRevokedTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordTokenRefresh records a refresh token exchange attempt
+func (m *Metrics) RecordTokenRefresh(result string) {
+	m.TokenRefreshTotal.WithLabelValues(result).Inc()
+}
+
+// RecordSessionReuseDetected records a revoked refresh token being presented again
+func (m *Metrics) RecordSessionReuseDetected() {
+	m.SessionReuseDetectedTotal.Inc()
+}
+
+// RecordRateLimitDecision records a rate limit decision ("allowed" or
+// "denied") for the policy matched against the request route.
+func (m *Metrics) RecordRateLimitDecision(route, decision string) {
+	m.RateLimitDecisionsTotal.WithLabelValues(route, decision).Inc()
+}
+
 // RecordDatabaseQuery records database query metrics
 func (m *Metrics) RecordDatabaseQuery(operation, table string, duration time.Duration) {
 	m.DatabaseQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
@@ -339,6 +562,14 @@ func (m *Metrics) UpdateBusinessMetrics(ctx context.Context, db *sql.DB) {
 	if err == nil {
 		m.ActiveUsersTotal.Set(float64(activeUsers))
 	}
+
+	// Update last-hour active users count, a tighter window more useful for
+	// operational alerting than the 30-day ActiveUsersTotal gauge.
+	var activeUsersLastHour int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM Accounts WHERE last_login > DATE_SUB(NOW(), INTERVAL 1 HOUR)").Scan(&activeUsersLastHour)
+	if err == nil {
+		m.ActiveUsersLastHour.Set(float64(activeUsersLastHour))
+	}
 }
 
 // StartMetricsUpdater starts a goroutine to periodically update metrics
@@ -355,4 +586,4 @@ func (m *Metrics) StartMetricsUpdater(ctx context.Context, db *sql.DB) {
 			m.UpdateBusinessMetrics(ctx, db)
 		}
 	}
-}
\ No newline at end of file
+}
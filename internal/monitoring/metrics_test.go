@@ -0,0 +1,93 @@
+package monitoring
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestVerificationOutcomeSanitizeBoundsCardinality(t *testing.T) {
+	cases := []struct {
+		name string
+		in   VerificationOutcome
+		want VerificationOutcome
+	}{
+		{
+			name: "allowed values pass through",
+			in:   VerificationOutcome{Reason: "expired", Origin: "sdk", ClientVersion: "1.4.2"},
+			want: VerificationOutcome{Reason: "expired", Origin: "sdk", ClientVersion: "1.4.2"},
+		},
+		{
+			name: "unknown reason is bucketed",
+			in:   VerificationOutcome{Reason: "blah; DROP TABLE licenses", Origin: "api", ClientVersion: "2"},
+			want: VerificationOutcome{Reason: otherLabel, Origin: "api", ClientVersion: "2"},
+		},
+		{
+			name: "unknown origin is bucketed",
+			in:   VerificationOutcome{Reason: "ok", Origin: "attacker-supplied-origin", ClientVersion: "1"},
+			want: VerificationOutcome{Reason: "ok", Origin: otherLabel, ClientVersion: "1"},
+		},
+		{
+			name: "free-form client version is bucketed",
+			in:   VerificationOutcome{Reason: "ok", Origin: "cli", ClientVersion: "<script>alert(1)</script>"},
+			want: VerificationOutcome{Reason: "ok", Origin: "cli", ClientVersion: unknownClientVersion},
+		},
+		{
+			name: "empty outcome is fully bucketed",
+			in:   VerificationOutcome{},
+			want: VerificationOutcome{Reason: otherLabel, Origin: otherLabel, ClientVersion: unknownClientVersion},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.in.sanitize()
+			if got != tc.want {
+				t.Errorf("sanitize() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMetricsSanitizeProductRejectsArbitraryCardinality(t *testing.T) {
+	// A flood of distinct product IDs (e.g. an unauthenticated caller of
+	// POST /license/verify making one up per request) must not grow the
+	// counter vector's product label past maxTrackedProducts.
+	m := NewMetrics(prometheus.NewRegistry())
+
+	seen := map[string]bool{}
+	for i := 0; i < maxTrackedProducts+1000; i++ {
+		seen[m.sanitizeProduct(fmt.Sprintf("attacker-product-%d", i))] = true
+	}
+	if len(seen) != maxTrackedProducts+1 {
+		t.Fatalf("expected maxTrackedProducts distinct values plus %q, got %d distinct values", otherLabel, len(seen))
+	}
+	if !seen[otherLabel] {
+		t.Fatalf("expected overflow products to be bucketed as %q", otherLabel)
+	}
+
+	// A product seen before the cap was reached keeps passing through
+	// verbatim even after the cap is reached, rather than itself getting
+	// bucketed once seenProducts is full.
+	if got := m.sanitizeProduct("attacker-product-0"); got != "attacker-product-0" {
+		t.Fatalf("expected an already-tracked product to keep passing through, got %q", got)
+	}
+}
+
+func TestMetricsRecordLicenseVerificationBoundsProductCardinality(t *testing.T) {
+	// End-to-end through the call site the review flagged: an
+	// unauthenticated caller sending arbitrary product IDs to
+	// RecordLicenseVerification must not be able to grow
+	// LicenseVerificationsTotal's cardinality without bound.
+	m := NewMetrics(prometheus.NewRegistry())
+
+	for i := 0; i < maxTrackedProducts+50; i++ {
+		m.RecordLicenseVerification(fmt.Sprintf("product-%d", i), VerificationOutcome{Reason: "ok", Origin: "api", ClientVersion: "1.0"})
+	}
+
+	if got := testutil.CollectAndCount(m.LicenseVerificationsTotal); got != maxTrackedProducts+1 {
+		t.Fatalf("expected maxTrackedProducts+1 distinct label combinations, got %d", got)
+	}
+}
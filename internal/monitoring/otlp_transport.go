@@ -0,0 +1,107 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// OTLPTransport ships batches to an OTLP/HTTP logs endpoint (e.g. an
+// OpenTelemetry Collector's "/v1/logs" receiver) using the JSON encoding
+// of the OTLP logs data model.
+type OTLPTransport struct {
+	client   *http.Client
+	endpoint string
+	headers  map[string]string
+}
+
+// NewOTLPTransport creates an OTLPTransport posting to endpoint (e.g.
+// "http://otel-collector:4318/v1/logs"). headers is sent with every
+// request, e.g. for an "Authorization" or tenant header; it may be nil.
+func NewOTLPTransport(endpoint string, headers map[string]string) *OTLPTransport {
+	return &OTLPTransport{client: newTransportHTTPClient(nil), endpoint: endpoint, headers: headers}
+}
+
+// otlpSeverityNumber maps a LogEntry.Level to the OTLP SeverityNumber
+// enum (logs data model section 2.2.4.2).
+func otlpSeverityNumber(level string) int {
+	switch level {
+	case "debug":
+		return 5 // DEBUG
+	case "info":
+		return 9 // INFO
+	case "warn":
+		return 13 // WARN
+	case "error":
+		return 17 // ERROR
+	default:
+		return 0 // UNSPECIFIED
+	}
+}
+
+type otlpExportRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpScopeLogs struct {
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           otlpAnyValue   `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// Send implements LogTransport.
+func (t *OTLPTransport) Send(ctx context.Context, entries []LogEntry) error {
+	records := make([]otlpLogRecord, 0, len(entries))
+	for _, entry := range entries {
+		attrs := []otlpKeyValue{
+			{Key: "service", Value: otlpAnyValue{StringValue: entry.Service}},
+			{Key: "version", Value: otlpAnyValue{StringValue: entry.Version}},
+		}
+		if entry.TraceID != "" {
+			attrs = append(attrs, otlpKeyValue{Key: "trace_id", Value: otlpAnyValue{StringValue: entry.TraceID}})
+		}
+		if entry.UserID != "" {
+			attrs = append(attrs, otlpKeyValue{Key: "user_id", Value: otlpAnyValue{StringValue: entry.UserID}})
+		}
+		if entry.Error != "" {
+			attrs = append(attrs, otlpKeyValue{Key: "error", Value: otlpAnyValue{StringValue: entry.Error}})
+		}
+
+		records = append(records, otlpLogRecord{
+			TimeUnixNano:   fmt.Sprintf("%d", entry.Timestamp.UnixNano()),
+			SeverityNumber: otlpSeverityNumber(entry.Level),
+			SeverityText:   entry.Level,
+			Body:           otlpAnyValue{StringValue: entry.Message},
+			Attributes:     attrs,
+		})
+	}
+
+	body, err := json.Marshal(otlpExportRequest{
+		ResourceLogs: []otlpResourceLogs{{ScopeLogs: []otlpScopeLogs{{LogRecords: records}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("otlp: batch marshal failed: %w", err)
+	}
+
+	return postGzipJSON(ctx, t.client, t.endpoint, "application/json", body, t.headers)
+}
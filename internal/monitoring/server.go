@@ -0,0 +1,78 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ServerConfig configures the standalone metrics HTTP server. It is kept
+// separate from the public API router so operators can bind it to a
+// loopback or internal-only address without exposing it publicly.
+type ServerConfig struct {
+	Address     string `yaml:"address"`
+	TLSCertFile string `yaml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file"`
+}
+
+// DefaultServerConfig returns the default metrics server configuration.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{Address: "127.0.0.1:9090"}
+}
+
+// Serve runs an independent HTTP server exposing /metrics (scraping reg),
+// /healthz, and /readyz. It blocks until ctx is cancelled or the server
+// fails to start, returning http.ErrServerClosed on a clean shutdown.
+func Serve(ctx context.Context, cfg ServerConfig, reg *prometheus.Registry) error {
+	if cfg.Address == "" {
+		cfg.Address = DefaultServerConfig().Address
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+
+	srv := &http.Server{
+		Addr:         cfg.Address,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		useTLS := cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
+		if useTLS {
+			errCh <- srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			errCh <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return http.ErrServerClosed
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+}
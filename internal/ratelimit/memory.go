@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// MemoryLimiter is a single-instance RateLimiter backed by per-key
+// token-bucket limiters. Entries are LRU-evicted once maxEntries is
+// exceeded and swept by a background janitor once idle for longer than
+// idleTTL, so a flood of distinct keys (e.g. spoofed IPs) can't grow the
+// map forever.
+type MemoryLimiter struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	maxEntries int
+	idleTTL    time.Duration
+	stopCh     chan struct{}
+}
+
+type memoryEntry struct {
+	key      string
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewMemoryLimiter creates a MemoryLimiter bounded to maxEntries keys, with
+// a background janitor evicting entries idle for longer than idleTTL.
+func NewMemoryLimiter(maxEntries int, idleTTL time.Duration) *MemoryLimiter {
+	m := &MemoryLimiter{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+		idleTTL:    idleTTL,
+		stopCh:     make(chan struct{}),
+	}
+	go m.janitor()
+	return m
+}
+
+// Close stops the background janitor. Safe to call once.
+func (m *MemoryLimiter) Close() {
+	close(m.stopCh)
+}
+
+func (m *MemoryLimiter) janitor() {
+	ticker := time.NewTicker(m.idleTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.evictIdle()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *MemoryLimiter) evictIdle() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-m.idleTTL)
+	for e := m.order.Back(); e != nil; {
+		entry := e.Value.(*memoryEntry)
+		if entry.lastSeen.After(cutoff) {
+			break
+		}
+		prev := e.Prev()
+		m.order.Remove(e)
+		delete(m.entries, entry.key)
+		e = prev
+	}
+}
+
+// Allow implements RateLimiter.
+func (m *MemoryLimiter) Allow(_ context.Context, key string, policy Policy) (Decision, error) {
+	limiter := m.limiterFor(key, policy)
+
+	now := time.Now()
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return Decision{Allowed: false, RetryAfter: policy.Interval, ResetAt: now.Add(policy.Interval)}, nil
+	}
+
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.Cancel()
+		return Decision{Allowed: false, RetryAfter: delay, ResetAt: now.Add(delay)}, nil
+	}
+
+	return Decision{Allowed: true, ResetAt: now}, nil
+}
+
+func (m *MemoryLimiter) limiterFor(key string, policy Policy) *rate.Limiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.lastSeen = time.Now()
+		m.order.MoveToFront(el)
+		return entry.limiter
+	}
+
+	ratePerSecond := float64(policy.RequestsPerInterval) / policy.Interval.Seconds()
+	entry := &memoryEntry{
+		key:      key,
+		limiter:  rate.NewLimiter(rate.Limit(ratePerSecond), policy.Burst),
+		lastSeen: time.Now(),
+	}
+	el := m.order.PushFront(entry)
+	m.entries[key] = el
+
+	if m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		if oldest != nil {
+			oldestEntry := oldest.Value.(*memoryEntry)
+			m.order.Remove(oldest)
+			delete(m.entries, oldestEntry.key)
+		}
+	}
+
+	return entry.limiter
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterAllowsBurstThenDenies(t *testing.T) {
+	limiter := NewMemoryLimiter(100, time.Minute)
+	defer limiter.Close()
+
+	policy := Policy{Name: "test", RequestsPerInterval: 2, Interval: time.Minute, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		decision, err := limiter.Allow(context.Background(), "ip:1.2.3.4", policy)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !decision.Allowed {
+			t.Fatalf("request %d: expected allowed, got denied", i)
+		}
+	}
+
+	decision, err := limiter.Allow(context.Background(), "ip:1.2.3.4", policy)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if decision.Allowed {
+		t.Fatal("expected the 3rd request over burst to be denied")
+	}
+	if decision.RetryAfter <= 0 {
+		t.Fatal("expected a positive RetryAfter on denial")
+	}
+}
+
+func TestMemoryLimiterEvictsBeyondMaxEntries(t *testing.T) {
+	limiter := NewMemoryLimiter(2, time.Minute)
+	defer limiter.Close()
+
+	policy := Policy{Name: "test", RequestsPerInterval: 10, Interval: time.Minute, Burst: 10}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := limiter.Allow(context.Background(), key, policy); err != nil {
+			t.Fatalf("Allow(%s): %v", key, err)
+		}
+	}
+
+	limiter.mu.Lock()
+	n := len(limiter.entries)
+	limiter.mu.Unlock()
+
+	if n > 2 {
+		t.Fatalf("expected at most 2 entries after eviction, got %d", n)
+	}
+}
+
+func TestPolicyRegistryFallsBackToDefault(t *testing.T) {
+	def := Policy{Name: "default", RequestsPerInterval: 100, Interval: time.Minute, Burst: 20}
+	reg := NewPolicyRegistry(def)
+	reg.Register("/license/verify", Policy{Name: "/license/verify", RequestsPerInterval: 5, Interval: time.Minute, Burst: 2})
+
+	if got := reg.Lookup("/license/verify"); got.RequestsPerInterval != 5 {
+		t.Fatalf("expected the registered policy, got %+v", got)
+	}
+	if got := reg.Lookup("/api/ping"); got.Name != "default" {
+		t.Fatalf("expected fallback policy for unregistered path, got %+v", got)
+	}
+}
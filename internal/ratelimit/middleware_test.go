@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fixedDecisionLimiter struct {
+	decision Decision
+	err      error
+}
+
+func (f fixedDecisionLimiter) Allow(_ context.Context, _ string, _ Policy) (Decision, error) {
+	return f.decision, f.err
+}
+
+func TestMiddlewareDeniesWithRetryAfterHeader(t *testing.T) {
+	limiter := fixedDecisionLimiter{decision: Decision{Allowed: false, RetryAfter: 30 * time.Second}}
+	registry := NewPolicyRegistry(Policy{Name: "default", RequestsPerInterval: 1, Interval: time.Minute, Burst: 1})
+
+	called := false
+	handler := Middleware(limiter, registry, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/license/verify", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if called {
+		t.Fatal("downstream handler should not run when the limiter denies the request")
+	}
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Fatalf("Retry-After = %q, want %q", got, "30")
+	}
+}
+
+func TestMiddlewareFailsOpenOnLimiterError(t *testing.T) {
+	limiter := fixedDecisionLimiter{err: context.DeadlineExceeded}
+	registry := NewPolicyRegistry(Policy{Name: "default", RequestsPerInterval: 1, Interval: time.Minute, Burst: 1})
+
+	called := false
+	handler := Middleware(limiter, registry, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/license/verify", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !called {
+		t.Fatal("expected the middleware to fail open and call the downstream handler on a limiter error")
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitKeyPrefersAuthenticatedUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	if got := rateLimitKey(req); got != "ip:10.0.0.1:1234" {
+		t.Fatalf("expected IP-keyed fallback for unauthenticated request, got %q", got)
+	}
+}
@@ -0,0 +1,67 @@
+// Package ratelimit provides request rate limiting shared across a single
+// instance (in-memory) or a horizontally-scaled fleet (Redis-backed), with
+// per-route policies and a per-authenticated-user/per-IP key.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Policy configures how many requests a key may make in Interval, with
+// Burst as the most requests a key may make at once (the bucket capacity
+// of the in-memory token-bucket implementation; the Redis fixed-window
+// implementation ignores Burst since a counter has no notion of burst).
+type Policy struct {
+	Name                string
+	RequestsPerInterval int
+	Interval            time.Duration
+	Burst               int
+}
+
+// Decision is the result of a rate limit check.
+type Decision struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAt    time.Time
+}
+
+// RateLimiter decides whether the caller identified by key, under policy,
+// may proceed.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (Decision, error)
+}
+
+// PolicyRegistry maps a request path to the Policy that should govern it,
+// falling back to a default policy for unregistered paths. Register every
+// route before the registry is used concurrently by Middleware; Lookup
+// itself is safe for concurrent use, but the map isn't guarded against
+// registration racing with lookups.
+type PolicyRegistry struct {
+	routes   map[string]Policy
+	fallback Policy
+}
+
+// NewPolicyRegistry creates a registry that returns fallback for any path
+// without a more specific policy registered via Register.
+func NewPolicyRegistry(fallback Policy) *PolicyRegistry {
+	return &PolicyRegistry{
+		routes:   make(map[string]Policy),
+		fallback: fallback,
+	}
+}
+
+// Register assigns policy to the exact request path.
+func (r *PolicyRegistry) Register(path string, policy Policy) {
+	r.routes[path] = policy
+}
+
+// Lookup returns the policy registered for path, or the registry's
+// fallback policy if none was registered.
+func (r *PolicyRegistry) Lookup(path string) Policy {
+	if policy, ok := r.routes[path]; ok {
+		return policy
+	}
+	return r.fallback
+}
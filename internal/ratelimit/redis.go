@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLimiter is a distributed RateLimiter backed by a fixed-window
+// counter in Redis (INCR + PEXPIRE), so every replica of a
+// horizontally-scaled server enforces the same limit for a given key.
+type RedisLimiter struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisLimiter creates a RedisLimiter that namespaces its keys under
+// keyPrefix (e.g. "ratelimit") to avoid colliding with other uses of the
+// same Redis instance.
+func NewRedisLimiter(client *redis.Client, keyPrefix string) *RedisLimiter {
+	return &RedisLimiter{client: client, keyPrefix: keyPrefix}
+}
+
+// Allow implements RateLimiter.
+func (r *RedisLimiter) Allow(ctx context.Context, key string, policy Policy) (Decision, error) {
+	redisKey := fmt.Sprintf("%s:%s", r.keyPrefix, key)
+
+	count, err := r.client.Incr(ctx, redisKey).Result()
+	if err != nil {
+		return Decision{}, fmt.Errorf("rate limit incr failed: %w", err)
+	}
+
+	if count == 1 {
+		if err := r.client.PExpire(ctx, redisKey, policy.Interval).Err(); err != nil {
+			return Decision{}, fmt.Errorf("rate limit pexpire failed: %w", err)
+		}
+	}
+
+	ttl, err := r.client.PTTL(ctx, redisKey).Result()
+	if err != nil {
+		return Decision{}, fmt.Errorf("rate limit pttl failed: %w", err)
+	}
+	if ttl < 0 {
+		ttl = policy.Interval
+	}
+	resetAt := time.Now().Add(ttl)
+
+	if int(count) > policy.RequestsPerInterval {
+		return Decision{Allowed: false, RetryAfter: ttl, ResetAt: resetAt}, nil
+	}
+
+	return Decision{
+		Allowed:   true,
+		Remaining: policy.RequestsPerInterval - int(count),
+		ResetAt:   resetAt,
+	}, nil
+}
@@ -0,0 +1,252 @@
+package license
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"license-server/database"
+)
+
+const (
+	bundleLicensesFile = "licenses.jsonl"
+	bundleAuditFile    = "audit.jsonl"
+	bundleSigFile      = "bundle.sig"
+)
+
+var (
+	ErrMalformedBundle        = errors.New("malformed license bundle")
+	ErrBundleSignatureInvalid = errors.New("license bundle signature invalid")
+)
+
+// BundleLicense is one licenses.jsonl row of an import/export bundle: the
+// same shape as database.License, minus the source instance's row ID and
+// activation bookkeeping, with ISO-8601 timestamps (time.Time already
+// marshals that way) so the file reads cleanly on whatever reads it next.
+type BundleLicense struct {
+	Key            string     `json:"key"`
+	Product        string     `json:"product"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	OwnerEmail     string     `json:"owner_email"`
+	OwnerName      string     `json:"owner_name"`
+	MaxActivations int        `json:"max_activations,omitempty"`
+	Features       string     `json:"features,omitempty"`
+}
+
+// BundleAuditLog is one audit.jsonl row: a point-in-time record of the
+// source instance's audit trail included for the operator's reference on
+// the receiving end. ImportBundle verifies it as part of the bundle's
+// signature but does not replay it.
+type BundleAuditLog struct {
+	Action     string    `json:"action"`
+	LicenseKey string    `json:"license_key"`
+	Product    string    `json:"product"`
+	ChangedAt  time.Time `json:"changed_at"`
+	Details    string    `json:"details,omitempty"`
+}
+
+// ExportBundle streams every license and audit log row in s's database
+// into a portable migration bundle: a tar.gz of licenses.jsonl and
+// audit.jsonl plus a detached bundle.sig Ed25519 signature (under
+// signer's key) over the un-gzipped tar. This lets an operator move
+// licenses between MySQL instances, or seed a staging environment from
+// production, without hand-rolling SQL dumps, and ImportBundle rejects
+// the bundle outright if it was tampered with in transit.
+//
+// Rows are streamed through database.Database.StreamLicenses/
+// StreamAuditLogs rather than loaded into a []License/[]AuditLog first,
+// so exporting a large table doesn't hold it in memory twice.
+func (s *Service) ExportBundle(signer *Signer, w io.Writer) error {
+	var licensesJSONL bytes.Buffer
+	licEnc := json.NewEncoder(&licensesJSONL)
+	if err := s.db.StreamLicenses(func(lic database.License) error {
+		return licEnc.Encode(BundleLicense{
+			Key:            lic.Key,
+			Product:        lic.Product,
+			ExpiresAt:      lic.ExpiresAt,
+			OwnerEmail:     lic.OwnerEmail,
+			OwnerName:      lic.OwnerName,
+			MaxActivations: lic.MaxActivations,
+			Features:       lic.Features,
+		})
+	}); err != nil {
+		return fmt.Errorf("licenses export failed: %w", err)
+	}
+
+	var auditJSONL bytes.Buffer
+	auditEnc := json.NewEncoder(&auditJSONL)
+	if err := s.db.StreamAuditLogs(func(log database.AuditLog) error {
+		return auditEnc.Encode(BundleAuditLog{
+			Action:     log.Action,
+			LicenseKey: log.LicenseKey,
+			Product:    log.Product,
+			ChangedAt:  log.ChangedAt,
+			Details:    log.Details,
+		})
+	}); err != nil {
+		return fmt.Errorf("audit log export failed: %w", err)
+	}
+
+	signedTar, err := buildBundleTar(licensesJSONL.Bytes(), auditJSONL.Bytes())
+	if err != nil {
+		return err
+	}
+	signature := ed25519.Sign(signer.priv, signedTar)
+
+	sigEntry, err := tarEntry(bundleSigFile, signature)
+	if err != nil {
+		return err
+	}
+
+	// signedTar is itself a complete, closed archive (it ends in tar's
+	// two-zero-block trailer); strip that trailer and append the sig
+	// entry plus a fresh one so the licenses/audit bytes are tar-encoded
+	// exactly once rather than built again from scratch for the
+	// on-the-wire archive.
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(signedTar[:len(signedTar)-tarTrailerSize]); err != nil {
+		return fmt.Errorf("bundle write failed: %w", err)
+	}
+	if _, err := gz.Write(sigEntry); err != nil {
+		return fmt.Errorf("bundle write failed: %w", err)
+	}
+	if _, err := gz.Write(tarTrailer); err != nil {
+		return fmt.Errorf("bundle write failed: %w", err)
+	}
+	return gz.Close()
+}
+
+// ImportBundle verifies r's detached bundle.sig against signer's public
+// key, then replays every row in its licenses.jsonl through the ordinary
+// AddLicense path — so duplicate detection and audit_log "ADD" entries
+// happen exactly as they would for a hand-entered license, and a
+// re-imported bundle surfaces database.ErrDuplicateKey like any other
+// repeated AddLicense. It returns the number of licenses imported before
+// any error; a partially-imported bundle is not rolled back, matching
+// AddLicense's own per-row semantics.
+func (s *Service) ImportBundle(signer *Signer, r io.Reader) (int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrMalformedBundle, err)
+	}
+	defer gz.Close()
+
+	var licensesJSONL, auditJSONL, signature []byte
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrMalformedBundle, err)
+		}
+
+		// A tar header's declared Size is attacker-controlled input: cap
+		// how much of each entry we'll decompress into memory regardless
+		// of what it claims, rather than trusting it up front.
+		raw, err := io.ReadAll(io.LimitReader(tr, maxBundleBytes+1))
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrMalformedBundle, err)
+		}
+		if len(raw) > maxBundleBytes {
+			return 0, fmt.Errorf("%w: entry %q exceeds the maximum bundle size", ErrMalformedBundle, hdr.Name)
+		}
+		switch hdr.Name {
+		case bundleLicensesFile:
+			licensesJSONL = raw
+		case bundleAuditFile:
+			auditJSONL = raw
+		case bundleSigFile:
+			signature = raw
+		}
+	}
+	if licensesJSONL == nil || signature == nil {
+		return 0, ErrMalformedBundle
+	}
+
+	signedTar, err := buildBundleTar(licensesJSONL, auditJSONL)
+	if err != nil {
+		return 0, err
+	}
+	if !ed25519.Verify(signer.PublicKey(), signedTar, signature) {
+		return 0, ErrBundleSignatureInvalid
+	}
+
+	var imported int
+	dec := json.NewDecoder(bytes.NewReader(licensesJSONL))
+	for dec.More() {
+		var bl BundleLicense
+		if err := dec.Decode(&bl); err != nil {
+			return imported, fmt.Errorf("%w: %v", ErrMalformedBundle, err)
+		}
+
+		entitlements, err := ParseEntitlements(bl.Features)
+		if err != nil {
+			return imported, err
+		}
+		if err := s.AddLicense(bl.Key, bl.Product, bl.OwnerEmail, bl.OwnerName, bl.ExpiresAt, bl.MaxActivations, entitlements); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+
+	return imported, nil
+}
+
+// buildBundleTar tars licensesJSONL and auditJSONL (in that order) under
+// fixed file names and zero-valued headers, so ExportBundle signs the
+// exact same bytes ImportBundle later reconstructs and verifies against —
+// the signature covers this tar, not the gzip wrapper around it.
+func buildBundleTar(licensesJSONL, auditJSONL []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := writeTarEntry(tw, bundleLicensesFile, licensesJSONL); err != nil {
+		return nil, err
+	}
+	if err := writeTarEntry(tw, bundleAuditFile, auditJSONL); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("bundle tar close failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+		return fmt.Errorf("bundle tar header failed (%s): %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("bundle tar write failed (%s): %w", name, err)
+	}
+	return nil
+}
+
+// tarTrailerSize is the width of tar's end-of-archive marker: two
+// 512-byte zero blocks. tarTrailer is that marker's literal bytes.
+const tarTrailerSize = 1024
+
+var tarTrailer = make([]byte, tarTrailerSize)
+
+// tarEntry tar-encodes a single (name, data) entry on its own, padded to
+// tar's block size via Flush rather than Close, so its bytes can be
+// concatenated with other entries (or with buildBundleTar's output, once
+// its own trailer is stripped) without re-encoding anything.
+func tarEntry(name string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := writeTarEntry(tw, name, data); err != nil {
+		return nil, err
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, fmt.Errorf("bundle tar flush failed (%s): %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,110 @@
+package license
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestBuildBundleTarDeterministic(t *testing.T) {
+	a, err := buildBundleTar([]byte(`{"key":"A"}`+"\n"), []byte(`{"action":"ADD"}`+"\n"))
+	if err != nil {
+		t.Fatalf("buildBundleTar: %v", err)
+	}
+	b, err := buildBundleTar([]byte(`{"key":"A"}`+"\n"), []byte(`{"action":"ADD"}`+"\n"))
+	if err != nil {
+		t.Fatalf("buildBundleTar: %v", err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Fatal("expected buildBundleTar to be deterministic for identical input")
+	}
+}
+
+func TestBundleSignatureRoundTrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	signer := NewSigner(priv)
+
+	signedTar, err := buildBundleTar([]byte(`{"key":"A"}`+"\n"), nil)
+	if err != nil {
+		t.Fatalf("buildBundleTar: %v", err)
+	}
+	signature := ed25519.Sign(signer.priv, signedTar)
+
+	if !ed25519.Verify(pub, signedTar, signature) {
+		t.Fatal("expected signature to verify against the signer's own public key")
+	}
+
+	tampered := append(append([]byte{}, signedTar...), 'X')
+	if ed25519.Verify(pub, tampered, signature) {
+		t.Fatal("expected verification to fail against tampered tar contents")
+	}
+}
+
+// TestExportBundleArchiveIsValidTar builds the same licenses+signature+
+// audit archive ExportBundle assembles by concatenating signedTar (minus
+// its trailer) with a separately tar-encoded sig entry, and checks
+// archive/tar can read back all three entries intact — i.e. that the
+// hand-spliced trailer arithmetic produces a well-formed tar stream, not
+// just bytes that happen to look right.
+func TestExportBundleArchiveIsValidTar(t *testing.T) {
+	licensesJSONL := []byte(`{"key":"A"}` + "\n")
+	auditJSONL := []byte(`{"action":"ADD"}` + "\n")
+	signature := []byte("fake-signature")
+
+	signedTar, err := buildBundleTar(licensesJSONL, auditJSONL)
+	if err != nil {
+		t.Fatalf("buildBundleTar: %v", err)
+	}
+	sigEntry, err := tarEntry(bundleSigFile, signature)
+	if err != nil {
+		t.Fatalf("tarEntry: %v", err)
+	}
+
+	var archive bytes.Buffer
+	archive.Write(signedTar[:len(signedTar)-tarTrailerSize])
+	archive.Write(sigEntry)
+	archive.Write(tarTrailer)
+
+	got := map[string][]byte{}
+	tr := tar.NewReader(&archive)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("reading entry %q: %v", hdr.Name, err)
+		}
+		got[hdr.Name] = data
+	}
+
+	if string(got[bundleLicensesFile]) != string(licensesJSONL) {
+		t.Fatalf("licenses.jsonl mismatch: got %q", got[bundleLicensesFile])
+	}
+	if string(got[bundleAuditFile]) != string(auditJSONL) {
+		t.Fatalf("audit.jsonl mismatch: got %q", got[bundleAuditFile])
+	}
+	if string(got[bundleSigFile]) != string(signature) {
+		t.Fatalf("bundle.sig mismatch: got %q", got[bundleSigFile])
+	}
+}
+
+func TestImportBundleRejectsUnsignedInput(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	signer := NewSigner(priv)
+
+	s := &Service{}
+	if _, err := s.ImportBundle(signer, bytes.NewReader([]byte("not a gzip file"))); err == nil {
+		t.Fatal("expected ImportBundle to reject non-gzip input")
+	}
+}
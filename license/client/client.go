@@ -0,0 +1,377 @@
+// Package client is an embeddable license checker for the products that
+// consume this server, modeled on the operator-license-checker pattern:
+// a background goroutine periodically revalidates a license and the host
+// application polls Valid()/Features() to gate premium behavior rather
+// than trusting a single check performed at startup.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"license-server/license"
+)
+
+// defaultRefreshInterval is how often a Client re-checks its license when
+// Config.RefreshInterval is unset.
+const defaultRefreshInterval = 1 * time.Hour
+
+// defaultGracePeriod is how long a Client keeps honoring its last-known-good
+// result after the server becomes unreachable, when Config.GracePeriod is
+// unset.
+const defaultGracePeriod = 72 * time.Hour
+
+// Event is emitted on Client state transitions: valid->invalid, a change
+// in the entitled feature set, or entering the offline grace period.
+type Event int
+
+const (
+	EventBecameInvalid Event = iota
+	EventFeaturesChanged
+	EventEnteredGracePeriod
+)
+
+func (e Event) String() string {
+	switch e {
+	case EventBecameInvalid:
+		return "became_invalid"
+	case EventFeaturesChanged:
+		return "features_changed"
+	case EventEnteredGracePeriod:
+		return "entered_grace_period"
+	default:
+		return "unknown"
+	}
+}
+
+// Config configures a Client.
+type Config struct {
+	// ServerURL is the base URL of the license server, e.g.
+	// "https://license.example.com". Unused if WithSignedToken is given.
+	ServerURL string
+	Key       string
+	Product   string
+	MachineID string
+
+	// RefreshInterval is how often the background goroutine re-checks the
+	// license. Defaults to defaultRefreshInterval.
+	RefreshInterval time.Duration
+
+	// CachePath, if set, persists the last-known-good check result to
+	// disk (mode 0600) so GracePeriod survives a process restart, not
+	// just a transient outage.
+	CachePath string
+
+	// GracePeriod is how long a live-HTTP Client keeps honoring its last
+	// valid result after ServerURL becomes unreachable. Defaults to
+	// defaultGracePeriod. Unused in offline (WithSignedToken) mode.
+	GracePeriod time.Duration
+}
+
+// Option customizes a Client beyond Config.
+type Option func(*Client)
+
+// WithSignedToken switches a Client into offline mode: instead of calling
+// ServerURL on every refresh, it re-verifies token against verifier using
+// the signed-token subsystem (see license.Verifier). Use this for air-gapped
+// deployments that were issued a signed offline license token.
+func WithSignedToken(verifier *license.Verifier, token string) Option {
+	return func(c *Client) {
+		c.verifier = verifier
+		c.token = token
+	}
+}
+
+// state is a Client's current view of its license.
+type state struct {
+	Valid     bool       `json:"valid"`
+	Reason    string     `json:"reason,omitempty"`
+	Tier      string     `json:"tier,omitempty"`
+	Features  []string   `json:"features,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CheckedAt time.Time  `json:"checked_at"`
+	Grace     bool       `json:"grace,omitempty"`
+}
+
+// Client periodically validates one license and exposes its current
+// state to the embedding application. The zero value is not usable; build
+// one with New.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+
+	verifier *license.Verifier
+	token    string
+
+	mu          sync.RWMutex
+	state       state
+	subscribers []chan Event
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// New builds a Client for cfg. Call Start to perform the first check and
+// begin the background refresh loop.
+func New(cfg Config, opts ...Option) *Client {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultRefreshInterval
+	}
+	if cfg.GracePeriod <= 0 {
+		cfg.GracePeriod = defaultGracePeriod
+	}
+	c := &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		stopCh:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Start loads any cached state, performs an immediate check, and launches
+// the background refresh loop. It does not return an error: a failed
+// initial check simply leaves the Client invalid (or, if a usable cache
+// exists, in its grace period) and Valid() reflects that.
+func (c *Client) Start() {
+	if cached, ok := c.loadCache(); ok {
+		c.mu.Lock()
+		c.state = cached
+		c.mu.Unlock()
+	}
+	c.refresh()
+	go c.loop()
+}
+
+// Stop ends the background refresh loop. Safe to call once; a second call
+// is a no-op.
+func (c *Client) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+}
+
+func (c *Client) loop() {
+	ticker := time.NewTicker(c.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.refresh()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// Valid reports whether the license was valid as of the last check (or
+// is currently within its offline grace period).
+func (c *Client) Valid() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Valid
+}
+
+// Features returns the entitled feature list as of the last check.
+func (c *Client) Features() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.state.Features...)
+}
+
+// ExpiresAt returns the license's expiry as of the last check, or nil if
+// it doesn't expire or hasn't been checked yet.
+func (c *Client) ExpiresAt() *time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.ExpiresAt
+}
+
+// Subscribe registers ch to receive Events on state transitions. Sends are
+// non-blocking: a subscriber that isn't ready to receive misses the event
+// rather than stalling the refresh loop, so ch should be buffered if the
+// caller cares about not missing one.
+func (c *Client) Subscribe(ch chan Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribers = append(c.subscribers, ch)
+}
+
+// refresh performs one check and updates state, emitting Events for any
+// transition it causes.
+func (c *Client) refresh() {
+	newState, transient, err := c.check()
+
+	c.mu.Lock()
+	old := c.state
+
+	if err != nil {
+		if transient && old.Valid && !old.CheckedAt.IsZero() && time.Since(old.CheckedAt) <= c.cfg.GracePeriod {
+			enteringGrace := !old.Grace
+			old.Grace = true
+			c.state = old
+			c.mu.Unlock()
+			if enteringGrace {
+				c.emit(EventEnteredGracePeriod)
+			}
+			return
+		}
+
+		c.state = state{Valid: false, Reason: err.Error(), CheckedAt: time.Now()}
+		c.mu.Unlock()
+		if old.Valid {
+			c.emit(EventBecameInvalid)
+		}
+		return
+	}
+
+	newState.CheckedAt = time.Now()
+	c.state = newState
+	c.mu.Unlock()
+
+	if transient {
+		c.persistCache(newState)
+	}
+	if old.Valid && !newState.Valid {
+		c.emit(EventBecameInvalid)
+	}
+	if !equalFeatures(old.Features, newState.Features) {
+		c.emit(EventFeaturesChanged)
+	}
+}
+
+// check performs one live-HTTP or offline verification, depending on
+// whether WithSignedToken was given. transient is true when err is the
+// kind of failure (network unreachable) that should fall back to the
+// cached grace period rather than being treated as a definitive rejection.
+func (c *Client) check() (s state, transient bool, err error) {
+	if c.verifier != nil {
+		s, err = c.verifyOffline()
+		return s, false, err
+	}
+	s, err = c.verifyLive()
+	return s, true, err
+}
+
+func (c *Client) verifyLive() (state, error) {
+	reqBody, err := json.Marshal(license.VerifyRequest{
+		Key:       c.cfg.Key,
+		Product:   c.cfg.Product,
+		MachineID: c.cfg.MachineID,
+	})
+	if err != nil {
+		return state{}, fmt.Errorf("verify request encode failed: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.cfg.ServerURL+"/license/verify", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return state{}, fmt.Errorf("verify request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var vr license.VerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&vr); err != nil {
+		return state{}, fmt.Errorf("verify response decode failed: %w", err)
+	}
+
+	s := state{Valid: vr.Valid, Reason: vr.Reason, ExpiresAt: vr.ExpiresAt}
+	if vr.Entitlements != nil {
+		s.Tier = vr.Entitlements.Tier
+		s.Features = vr.Entitlements.Features
+	}
+	return s, nil
+}
+
+func (c *Client) verifyOffline() (state, error) {
+	claims, err := c.verifier.Verify(c.token)
+	if err != nil {
+		return state{Valid: false, Reason: err.Error()}, nil
+	}
+
+	var expiresAt *time.Time
+	if claims.ExpiresAt != 0 {
+		t := time.Unix(claims.ExpiresAt, 0)
+		expiresAt = &t
+	}
+	return state{Valid: true, Features: claims.Features, ExpiresAt: expiresAt}, nil
+}
+
+// markRevoked forces c invalid because it was pulled off the server's
+// revocation list (see RevocationPoller), emitting EventBecameInvalid if
+// it was previously valid. Unlike refresh's network-failure handling, a
+// revocation is authoritative: no grace period applies.
+func (c *Client) markRevoked() {
+	c.mu.Lock()
+	wasValid := c.state.Valid
+	c.state = state{Valid: false, Reason: "license revoked", CheckedAt: time.Now()}
+	c.mu.Unlock()
+
+	if wasValid {
+		c.emit(EventBecameInvalid)
+	}
+}
+
+func (c *Client) emit(e Event) {
+	c.mu.RLock()
+	subs := append([]chan Event(nil), c.subscribers...)
+	c.mu.RUnlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (c *Client) loadCache() (state, bool) {
+	if c.cfg.CachePath == "" {
+		return state{}, false
+	}
+	raw, err := os.ReadFile(c.cfg.CachePath)
+	if err != nil {
+		return state{}, false
+	}
+	var s state
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return state{}, false
+	}
+	return s, true
+}
+
+func (c *Client) persistCache(s state) {
+	if c.cfg.CachePath == "" {
+		return
+	}
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cfg.CachePath, raw, 0o600)
+}
+
+func equalFeatures(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, f := range a {
+		seen[f]++
+	}
+	for _, f := range b {
+		seen[f]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,256 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"license-server/license"
+)
+
+func newTestServer(t *testing.T, respond func(req license.VerifyRequest) license.VerifyResponse) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req license.VerifyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(respond(req))
+	}))
+}
+
+func TestClientExpiry(t *testing.T) {
+	srv := newTestServer(t, func(req license.VerifyRequest) license.VerifyResponse {
+		return license.VerifyResponse{Valid: false, Reason: "license expired"}
+	})
+	defer srv.Close()
+
+	c := New(Config{ServerURL: srv.URL, Key: "KEY", Product: "product", RefreshInterval: time.Hour})
+	c.Start()
+	defer c.Stop()
+
+	if c.Valid() {
+		t.Fatal("expected an expired license to be invalid")
+	}
+}
+
+func TestClientNetworkFailureFallsBackToGrace(t *testing.T) {
+	srv := newTestServer(t, func(req license.VerifyRequest) license.VerifyResponse {
+		return license.VerifyResponse{
+			Valid:        true,
+			Entitlements: &license.EntitlementsView{Tier: "pro", Features: []string{"api_access"}},
+		}
+	})
+
+	c := New(Config{ServerURL: srv.URL, Key: "KEY", Product: "product", RefreshInterval: time.Hour, GracePeriod: time.Hour})
+	c.Start()
+	defer c.Stop()
+
+	if !c.Valid() {
+		t.Fatal("expected initial check to succeed")
+	}
+
+	// Simulate the server becoming unreachable.
+	srv.Close()
+
+	events := make(chan Event, 4)
+	c.Subscribe(events)
+	c.refresh()
+
+	if !c.Valid() {
+		t.Fatal("expected grace period to keep the license valid once the server is unreachable")
+	}
+	select {
+	case e := <-events:
+		if e != EventEnteredGracePeriod {
+			t.Fatalf("expected EventEnteredGracePeriod, got %v", e)
+		}
+	default:
+		t.Fatal("expected an EventEnteredGracePeriod event")
+	}
+}
+
+func TestClientRevocation(t *testing.T) {
+	valid := int32(1)
+	srv := newTestServer(t, func(req license.VerifyRequest) license.VerifyResponse {
+		if atomic.LoadInt32(&valid) == 1 {
+			return license.VerifyResponse{Valid: true, Entitlements: &license.EntitlementsView{Tier: "pro"}}
+		}
+		return license.VerifyResponse{Valid: false, Reason: "license revoked"}
+	})
+	defer srv.Close()
+
+	c := New(Config{ServerURL: srv.URL, Key: "KEY", Product: "product", RefreshInterval: time.Hour})
+	c.Start()
+	defer c.Stop()
+
+	if !c.Valid() {
+		t.Fatal("expected initial check to succeed")
+	}
+
+	events := make(chan Event, 4)
+	c.Subscribe(events)
+
+	atomic.StoreInt32(&valid, 0)
+	c.refresh()
+
+	if c.Valid() {
+		t.Fatal("expected a revoked license to be invalid after refresh")
+	}
+	select {
+	case e := <-events:
+		if e != EventBecameInvalid {
+			t.Fatalf("expected EventBecameInvalid, got %v", e)
+		}
+	default:
+		t.Fatal("expected an EventBecameInvalid event")
+	}
+}
+
+func TestClientFeaturesChanged(t *testing.T) {
+	features := []string{"api_access"}
+	srv := newTestServer(t, func(req license.VerifyRequest) license.VerifyResponse {
+		return license.VerifyResponse{Valid: true, Entitlements: &license.EntitlementsView{Tier: "pro", Features: features}}
+	})
+	defer srv.Close()
+
+	c := New(Config{ServerURL: srv.URL, Key: "KEY", Product: "product", RefreshInterval: time.Hour})
+	c.Start()
+	defer c.Stop()
+
+	events := make(chan Event, 4)
+	c.Subscribe(events)
+
+	features = []string{"api_access", "sso"}
+	c.refresh()
+
+	if got := c.Features(); len(got) != 2 {
+		t.Fatalf("expected 2 features after upgrade, got %v", got)
+	}
+	select {
+	case e := <-events:
+		if e != EventFeaturesChanged {
+			t.Fatalf("expected EventFeaturesChanged, got %v", e)
+		}
+	default:
+		t.Fatal("expected an EventFeaturesChanged event")
+	}
+}
+
+func TestClientSignedTokenOffline(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := license.NewSigner(priv)
+	verifier := license.NewVerifier(pub)
+
+	token, err := signer.Sign(license.TokenClaims{
+		Key:      "KEY",
+		Product:  "product",
+		Features: []string{"api_access"},
+	})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	c := New(Config{RefreshInterval: time.Hour}, WithSignedToken(verifier, token))
+	c.Start()
+	defer c.Stop()
+
+	if !c.Valid() {
+		t.Fatal("expected a validly signed token to verify offline")
+	}
+	if got := c.Features(); len(got) != 1 || got[0] != "api_access" {
+		t.Fatalf("expected features [api_access], got %v", got)
+	}
+}
+
+func TestClientSignedTokenRejectsBadSignature(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := license.NewSigner(priv)
+	verifier := license.NewVerifier(otherPub)
+
+	token, err := signer.Sign(license.TokenClaims{Key: "KEY", Product: "product"})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	c := New(Config{RefreshInterval: time.Hour}, WithSignedToken(verifier, token))
+	c.Start()
+	defer c.Stop()
+
+	if c.Valid() {
+		t.Fatal("expected a token signed by an untrusted key to be invalid")
+	}
+}
+
+func newCRLTestServer(t *testing.T, entries []license.RevocationEntry) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entries)
+	}))
+}
+
+func TestRevocationPollerMarksRevoked(t *testing.T) {
+	verifySrv := newTestServer(t, func(req license.VerifyRequest) license.VerifyResponse {
+		return license.VerifyResponse{Valid: true}
+	})
+	defer verifySrv.Close()
+
+	c := New(Config{ServerURL: verifySrv.URL, Key: "KEY", Product: "product", RefreshInterval: time.Hour})
+	c.Start()
+	defer c.Stop()
+
+	if !c.Valid() {
+		t.Fatal("expected initial check to succeed")
+	}
+
+	crlSrv := newCRLTestServer(t, []license.RevocationEntry{
+		{Key: "KEY", Product: "product", RevokedAt: time.Now()},
+	})
+	defer crlSrv.Close()
+
+	poller := NewRevocationPoller(c, crlSrv.URL, time.Hour)
+	poller.poll()
+
+	if c.Valid() {
+		t.Fatal("expected a revoked license to be invalid after a CRL poll")
+	}
+}
+
+func TestRevocationPollerIgnoresOtherLicenses(t *testing.T) {
+	verifySrv := newTestServer(t, func(req license.VerifyRequest) license.VerifyResponse {
+		return license.VerifyResponse{Valid: true}
+	})
+	defer verifySrv.Close()
+
+	c := New(Config{ServerURL: verifySrv.URL, Key: "KEY", Product: "product", RefreshInterval: time.Hour})
+	c.Start()
+	defer c.Stop()
+
+	crlSrv := newCRLTestServer(t, []license.RevocationEntry{
+		{Key: "OTHER-KEY", Product: "product", RevokedAt: time.Now()},
+	})
+	defer crlSrv.Close()
+
+	poller := NewRevocationPoller(c, crlSrv.URL, time.Hour)
+	poller.poll()
+
+	if !c.Valid() {
+		t.Fatal("expected a revocation for a different license to leave c valid")
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"license-server/license"
+)
+
+// defaultRevocationPollInterval is how often a RevocationPoller downloads
+// the CRL when its interval argument is unset.
+const defaultRevocationPollInterval = 15 * time.Minute
+
+// RevocationPoller periodically downloads a server's CRL
+// (GET /license/revocations.json) and marks target invalid the moment its
+// (key, product) appears on it, independent of target's own refresh
+// interval — a revocation should take effect faster than the next
+// scheduled full verification.
+type RevocationPoller struct {
+	serverURL  string
+	interval   time.Duration
+	httpClient *http.Client
+	target     *Client
+
+	mu    sync.Mutex
+	etag  string
+	since time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewRevocationPoller builds a RevocationPoller for target against
+// serverURL. interval <= 0 uses defaultRevocationPollInterval.
+func NewRevocationPoller(target *Client, serverURL string, interval time.Duration) *RevocationPoller {
+	if interval <= 0 {
+		interval = defaultRevocationPollInterval
+	}
+	return &RevocationPoller{
+		serverURL:  serverURL,
+		interval:   interval,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		target:     target,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start performs an immediate poll and launches the background polling
+// loop.
+func (p *RevocationPoller) Start() {
+	p.poll()
+	go p.loop()
+}
+
+// Stop ends the background polling loop. Safe to call once.
+func (p *RevocationPoller) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+}
+
+func (p *RevocationPoller) loop() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.poll()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// poll downloads the CRL and applies it to p.target. A failed poll (the
+// server is unreachable, say) is silently retried next interval — it
+// doesn't affect target's own grace period, which is driven solely by
+// target's own refresh loop.
+func (p *RevocationPoller) poll() {
+	p.mu.Lock()
+	since, etag := p.since, p.etag
+	p.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, p.serverURL+"/license/revocations.json?since="+strconv.FormatInt(since.Unix(), 10), nil)
+	if err != nil {
+		return
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	var entries []license.RevocationEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	if newEtag := resp.Header.Get("ETag"); newEtag != "" {
+		p.etag = newEtag
+	}
+	for _, e := range entries {
+		if e.RevokedAt.After(p.since) {
+			p.since = e.RevokedAt
+		}
+	}
+	p.mu.Unlock()
+
+	for _, e := range entries {
+		if e.Key == p.target.cfg.Key && e.Product == p.target.cfg.Product {
+			p.target.markRevoked()
+			return
+		}
+	}
+}
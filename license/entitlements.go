@@ -0,0 +1,123 @@
+package license
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Entitlements is the tiered feature/limit set attached to a license,
+// modeled on Coder's entitlements struct: a tier name, a flat list of
+// gated features, and named numeric limits (e.g. seats).
+type Entitlements struct {
+	TierName    string         `json:"tier,omitempty"`
+	FeatureList []string       `json:"features,omitempty"`
+	Limits      map[string]int `json:"limits,omitempty"`
+}
+
+// ParseEntitlements decodes raw (the licenses.features column) into an
+// Entitlements. An empty raw value is a license with no entitlements
+// configured, not an error.
+func ParseEntitlements(raw string) (Entitlements, error) {
+	if raw == "" {
+		return Entitlements{}, nil
+	}
+	var e Entitlements
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		return Entitlements{}, fmt.Errorf("entitlements parse failed: %w", err)
+	}
+	return e, nil
+}
+
+// JSON serializes e for storage, returning "" for a zero-value
+// Entitlements so an unconfigured license keeps an empty features column
+// rather than a literal "{}".
+func (e Entitlements) JSON() (string, error) {
+	if e.TierName == "" && len(e.FeatureList) == 0 && len(e.Limits) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("entitlements marshal failed: %w", err)
+	}
+	return string(b), nil
+}
+
+// Has reports whether feature is in e's feature list.
+func (e Entitlements) Has(feature string) bool {
+	for _, f := range e.FeatureList {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Tier returns e's tier name, or "" if unset.
+func (e Entitlements) Tier() string {
+	return e.TierName
+}
+
+// Limit returns the named limit, or 0 if it isn't set.
+func (e Entitlements) Limit(name string) int {
+	return e.Limits[name]
+}
+
+// diffEntitlements describes what changed between old and updated as a
+// short audit_log-friendly string (e.g. "tier: pro -> enterprise; added:
+// sso; removed: trial_banner"), or "" if neither the tier nor the feature
+// list changed. Limits are not compared.
+func diffEntitlements(old, updated Entitlements) string {
+	var parts []string
+
+	if old.TierName != updated.TierName {
+		parts = append(parts, fmt.Sprintf("tier: %s -> %s", displayOrNone(old.TierName), displayOrNone(updated.TierName)))
+	}
+
+	added, removed := diffFeatures(old.FeatureList, updated.FeatureList)
+	if len(added) > 0 {
+		parts = append(parts, fmt.Sprintf("added: %s", strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("removed: %s", strings.Join(removed, ", ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// diffFeatures returns the features present in updated but not old
+// (added) and present in old but not updated (removed), both sorted for
+// a stable audit log message.
+func diffFeatures(old, updated []string) (added, removed []string) {
+	oldSet := make(map[string]bool, len(old))
+	for _, f := range old {
+		oldSet[f] = true
+	}
+	updatedSet := make(map[string]bool, len(updated))
+	for _, f := range updated {
+		updatedSet[f] = true
+	}
+
+	for _, f := range updated {
+		if !oldSet[f] {
+			added = append(added, f)
+		}
+	}
+	for _, f := range old {
+		if !updatedSet[f] {
+			removed = append(removed, f)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func displayOrNone(tier string) string {
+	if tier == "" {
+		return "(none)"
+	}
+	return tier
+}
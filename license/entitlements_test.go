@@ -0,0 +1,101 @@
+package license
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEntitlementsEmpty(t *testing.T) {
+	e, err := ParseEntitlements("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Tier() != "" || e.Has("anything") || e.Limit("seats") != 0 {
+		t.Fatalf("expected zero-value entitlements, got %+v", e)
+	}
+}
+
+func TestParseEntitlementsRoundTrip(t *testing.T) {
+	raw := `{"tier":"pro","features":["api_access","sso"],"limits":{"seats":25}}`
+	e, err := ParseEntitlements(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Tier() != "pro" {
+		t.Fatalf("expected tier pro, got %q", e.Tier())
+	}
+	if !e.Has("sso") || e.Has("missing_feature") {
+		t.Fatalf("Has behaved unexpectedly: %+v", e)
+	}
+	if e.Limit("seats") != 25 {
+		t.Fatalf("expected seats limit 25, got %d", e.Limit("seats"))
+	}
+}
+
+func TestParseEntitlementsMalformed(t *testing.T) {
+	if _, err := ParseEntitlements("{not json"); err == nil {
+		t.Fatal("expected error for malformed entitlements JSON")
+	}
+}
+
+func TestEntitlementsJSONRoundTrip(t *testing.T) {
+	e := Entitlements{TierName: "enterprise", FeatureList: []string{"sso"}, Limits: map[string]int{"seats": 100}}
+	raw, err := e.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := ParseEntitlements(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(e, parsed) {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", parsed, e)
+	}
+}
+
+func TestEntitlementsJSONEmpty(t *testing.T) {
+	raw, err := Entitlements{}.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if raw != "" {
+		t.Fatalf("expected empty JSON for zero-value entitlements, got %q", raw)
+	}
+}
+
+func TestDiffEntitlementsUpgrade(t *testing.T) {
+	old := Entitlements{TierName: "basic", FeatureList: []string{"api_access"}}
+	upgraded := Entitlements{TierName: "pro", FeatureList: []string{"api_access", "sso"}}
+
+	diff := diffEntitlements(old, upgraded)
+	if diff == "" {
+		t.Fatal("expected a non-empty diff for an upgrade")
+	}
+	if !strings.Contains(diff, "tier: basic -> pro") {
+		t.Errorf("diff missing tier change: %q", diff)
+	}
+	if !strings.Contains(diff, "added: sso") {
+		t.Errorf("diff missing added feature: %q", diff)
+	}
+}
+
+func TestDiffEntitlementsDowngrade(t *testing.T) {
+	old := Entitlements{TierName: "pro", FeatureList: []string{"api_access", "sso"}}
+	downgraded := Entitlements{TierName: "basic", FeatureList: []string{"api_access"}}
+
+	diff := diffEntitlements(old, downgraded)
+	if !strings.Contains(diff, "tier: pro -> basic") {
+		t.Errorf("diff missing tier change: %q", diff)
+	}
+	if !strings.Contains(diff, "removed: sso") {
+		t.Errorf("diff missing removed feature: %q", diff)
+	}
+}
+
+func TestDiffEntitlementsNoChange(t *testing.T) {
+	e := Entitlements{TierName: "pro", FeatureList: []string{"sso"}}
+	if diff := diffEntitlements(e, e); diff != "" {
+		t.Fatalf("expected no diff for identical entitlements, got %q", diff)
+	}
+}
@@ -1,25 +1,69 @@
 package license
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"license-server/database"
+	"license-server/internal/monitoring"
 )
 
 type VerifyRequest struct {
-	Key     string `json:"key"`
-	Product string `json:"product"`
+	Key              string   `json:"key"`
+	Product          string   `json:"product"`
+	Origin           string   `json:"origin,omitempty"`
+	ClientVersion    string   `json:"client_version,omitempty"`
+	MachineID        string   `json:"machine_id,omitempty"`
+	Fingerprint      string   `json:"fingerprint,omitempty"`
+	Hostname         string   `json:"hostname,omitempty"`
+	RequiredFeatures []string `json:"required_features,omitempty"`
+	RequiredTier     string   `json:"required_tier,omitempty"`
 }
 
 type VerifyResponse struct {
-	Valid       bool       `json:"valid"`
-	Reason      string     `json:"reason,omitempty"`
-	Key         string     `json:"key,omitempty"`
-	Product     string     `json:"product,omitempty"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
-	OwnerEmail  string     `json:"owner_email,omitempty"`
-	OwnerName   string     `json:"owner_name,omitempty"`
-	IsActivated bool       `json:"is_activated,omitempty"`
+	Valid        bool              `json:"valid"`
+	Reason       string            `json:"reason,omitempty"`
+	Key          string            `json:"key,omitempty"`
+	Product      string            `json:"product,omitempty"`
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`
+	OwnerEmail   string            `json:"owner_email,omitempty"`
+	OwnerName    string            `json:"owner_name,omitempty"`
+	IsActivated  bool              `json:"is_activated,omitempty"`
+	Entitlements *EntitlementsView `json:"entitlements,omitempty"`
+}
+
+// EntitlementsView is the wire representation of an Entitlements, included
+// in a successful VerifyResponse so a client can gate its own UI.
+type EntitlementsView struct {
+	Tier     string         `json:"tier,omitempty"`
+	Features []string       `json:"features,omitempty"`
+	Limits   map[string]int `json:"limits,omitempty"`
+}
+
+type DeactivateRequest struct {
+	Key       string `json:"key"`
+	Product   string `json:"product"`
+	MachineID string `json:"machine_id"`
+}
+
+type DeactivateResponse struct {
+	Deactivated bool   `json:"deactivated"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type ActivationResponse struct {
+	MachineID string    `json:"machine_id"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	IP        string    `json:"ip,omitempty"`
+	Hostname  string    `json:"hostname,omitempty"`
 }
 
 type AuditLogResponse struct {
@@ -30,12 +74,134 @@ type AuditLogResponse struct {
 	Details    string    `json:"details,omitempty"`
 }
 
+type RevokeRequest struct {
+	Key     string `json:"key"`
+	Product string `json:"product"`
+	Reason  string `json:"reason"`
+}
+
+type RevokeResponse struct {
+	Revoked bool `json:"revoked"`
+}
+
+// RevocationEntry is the wire representation of one revocations row,
+// returned by GetRevocations for CRL pull distribution and consumed by
+// license/client's RevocationPoller.
+type RevocationEntry struct {
+	Key       string    `json:"key"`
+	Product   string    `json:"product"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type VerifyTokenRequest struct {
+	Token string `json:"token"`
+}
+
+type VerifyTokenResponse struct {
+	Valid          bool     `json:"valid"`
+	Reason         string   `json:"reason,omitempty"`
+	Key            string   `json:"key,omitempty"`
+	Product        string   `json:"product,omitempty"`
+	OwnerEmail     string   `json:"owner_email,omitempty"`
+	OwnerName      string   `json:"owner_name,omitempty"`
+	ExpiresAt      int64    `json:"expires_at,omitempty"`
+	Features       []string `json:"features,omitempty"`
+	MaxActivations int      `json:"max_activations,omitempty"`
+}
+
 type Handler struct {
-	service *Service
+	service      *Service
+	metrics      *monitoring.Metrics
+	verifier     *Verifier
+	artifactKeys *ArtifactKeySet
+	ticketSigner *Signer
+}
+
+// ActivateTicketRequest is the body of POST /api/license/activate.
+type ActivateTicketRequest struct {
+	LicenseKey        string `json:"license_key"`
+	Product           string `json:"product"`
+	DeviceFingerprint string `json:"device_fingerprint"`
+}
+
+// ActivateTicketResponse carries the signed offline activation ticket a
+// downstream client verifies locally with pkg/ticket.Verify.
+type ActivateTicketResponse struct {
+	Ticket string `json:"ticket"`
+}
+
+// UploadLicenseResponse is the wire representation of a license created
+// via Handler.UploadLicense.
+type UploadLicenseResponse struct {
+	ID      int    `json:"id"`
+	Key     string `json:"key"`
+	Product string `json:"product"`
+}
+
+// maxArtifactBytes bounds the multipart "license" field Handler.UploadLicense
+// will read, well above any real signed artifact, to keep a malicious
+// upload from exhausting memory.
+const maxArtifactBytes = 1 << 20
+
+// maxBundleBytes bounds the multipart "bundle" field Handler.ImportBundle
+// will read. A migration bundle holds a whole licenses table, so this is
+// far larger than maxArtifactBytes, but still bounded to keep a malicious
+// upload from exhausting memory.
+const maxBundleBytes = 1 << 28
+
+// ImportBundleResponse is the wire representation of a bundle accepted by
+// Handler.ImportBundle.
+type ImportBundleResponse struct {
+	Imported int `json:"imported"`
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a new context carrying actor — the identity of
+// whoever is about to perform an admin-gated license action — so
+// Handler.Revoke can record it in the audit log. The caller authenticates
+// the request (e.g. main.go's JWT admin middleware) before setting this;
+// Handler itself performs no authentication.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
 }
 
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "unknown"
+}
+
+func NewHandler(service *Service, metrics *monitoring.Metrics) *Handler {
+	return &Handler{service: service, metrics: metrics}
+}
+
+// WithTokenVerifier attaches an offline-token Verifier, enabling
+// VerifyToken. Without one, VerifyToken responds 503: the server was
+// started without a signing key configured.
+func (h *Handler) WithTokenVerifier(verifier *Verifier) *Handler {
+	h.verifier = verifier
+	return h
+}
+
+// WithArtifactKeys attaches the compiled-in public keys trusted to sign
+// uploaded license artifacts, enabling UploadLicense. Without one,
+// UploadLicense responds 503: the server was started without any trusted
+// signing keys configured.
+func (h *Handler) WithArtifactKeys(keys *ArtifactKeySet) *Handler {
+	h.artifactKeys = keys
+	return h
+}
+
+// WithTicketSigner attaches the Ed25519 signer used to issue device-bound
+// offline activation tickets and to sign/verify license migration
+// bundles, enabling ActivateTicket, ExportBundle, and ImportBundle.
+// Without one, those respond 503: the server was started without a
+// signing key configured.
+func (h *Handler) WithTicketSigner(signer *Signer) *Handler {
+	h.ticketSigner = signer
+	return h
 }
 
 func (h *Handler) VerifyLicense(w http.ResponseWriter, r *http.Request) {
@@ -50,8 +216,18 @@ func (h *Handler) VerifyLicense(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.service.QuickVerify(req.Key, req.Product) {
+		h.recordVerification(req, "forged")
+		sendResponse(w, VerifyResponse{
+			Valid:  false,
+			Reason: "License not found",
+		})
+		return
+	}
+
 	license, err := h.service.GetLicense(req.Key, req.Product)
 	if err != nil {
+		h.recordVerification(req, "not_found")
 		sendResponse(w, VerifyResponse{
 			Valid:  false,
 			Reason: "License not found",
@@ -59,7 +235,22 @@ func (h *Handler) VerifyLicense(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	revocation, err := h.service.IsRevoked(req.Key, req.Product)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if revocation != nil {
+		h.recordVerification(req, "revoked")
+		sendResponse(w, VerifyResponse{
+			Valid:  false,
+			Reason: "license revoked",
+		})
+		return
+	}
+
 	if license.ExpiresAt != nil && time.Now().After(*license.ExpiresAt) {
+		h.recordVerification(req, "expired")
 		sendResponse(w, VerifyResponse{
 			Valid:  false,
 			Reason: "License expired",
@@ -67,6 +258,48 @@ func (h *Handler) VerifyLicense(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	entitlements, err := ParseEntitlements(license.Features)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if req.RequiredTier != "" && entitlements.Tier() != req.RequiredTier {
+		h.recordVerification(req, "tier_not_entitled")
+		sendResponse(w, VerifyResponse{
+			Valid:  false,
+			Reason: "tier not entitled: " + req.RequiredTier,
+		})
+		return
+	}
+	for _, feature := range req.RequiredFeatures {
+		if !entitlements.Has(feature) {
+			h.recordVerification(req, "feature_not_entitled")
+			sendResponse(w, VerifyResponse{
+				Valid:  false,
+				Reason: "feature not entitled: " + feature,
+			})
+			return
+		}
+	}
+
+	if req.MachineID != "" {
+		err := h.service.ActivateMachine(license, req.MachineID, req.Fingerprint, clientIP(r), req.Hostname)
+		if errors.Is(err, database.ErrMaxActivationsExceeded) {
+			h.recordVerification(req, "max_activations_exceeded")
+			sendResponse(w, VerifyResponse{
+				Valid:  false,
+				Reason: "maximum activations exceeded",
+			})
+			return
+		}
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.recordVerification(req, "ok")
 	sendResponse(w, VerifyResponse{
 		Valid:       true,
 		Key:         license.Key,
@@ -75,6 +308,65 @@ func (h *Handler) VerifyLicense(w http.ResponseWriter, r *http.Request) {
 		OwnerEmail:  license.OwnerEmail,
 		OwnerName:   license.OwnerName,
 		IsActivated: license.IsActivated,
+		Entitlements: &EntitlementsView{
+			Tier:     entitlements.Tier(),
+			Features: entitlements.FeatureList,
+			Limits:   entitlements.Limits,
+		},
+	})
+}
+
+// VerifyToken verifies a signed offline license token entirely in-process
+// against the server's own public key — no database round trip, the same
+// check a downstream client does embedding that key itself.
+func (h *Handler) VerifyToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.verifier == nil {
+		http.Error(w, "Token verification not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req VerifyTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := h.verifier.Verify(req.Token)
+	if err != nil {
+		sendTokenResponse(w, VerifyTokenResponse{Valid: false, Reason: err.Error()})
+		return
+	}
+
+	sendTokenResponse(w, VerifyTokenResponse{
+		Valid:          true,
+		Key:            claims.Key,
+		Product:        claims.Product,
+		OwnerEmail:     claims.OwnerEmail,
+		OwnerName:      claims.OwnerName,
+		ExpiresAt:      claims.ExpiresAt,
+		Features:       claims.Features,
+		MaxActivations: claims.MaxActivations,
+	})
+}
+
+func sendTokenResponse(w http.ResponseWriter, resp VerifyTokenResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) recordVerification(req VerifyRequest, reason string) {
+	if h.metrics == nil {
+		return
+	}
+	h.metrics.RecordLicenseVerification(req.Product, monitoring.VerificationOutcome{
+		Reason:        reason,
+		Origin:        req.Origin,
+		ClientVersion: req.ClientVersion,
 	})
 }
 
@@ -103,4 +395,392 @@ func (h *Handler) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
 func sendResponse(w http.ResponseWriter, resp VerifyResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
-}
\ No newline at end of file
+}
+
+// Deactivate frees a machine's activation slot, e.g. when a customer
+// retires a machine and wants the slot back for a replacement.
+func (h *Handler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DeactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	license, err := h.service.GetLicense(req.Key, req.Product)
+	if err != nil {
+		http.Error(w, "License not found", http.StatusNotFound)
+		return
+	}
+
+	err = h.service.DeactivateMachine(license, req.MachineID)
+	if errors.Is(err, database.ErrMachineNotActivated) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DeactivateResponse{Deactivated: false, Reason: "machine not activated"})
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DeactivateResponse{Deactivated: true})
+}
+
+// ListActivations lists the machines currently bound to ?key=&product=.
+func (h *Handler) ListActivations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	product := r.URL.Query().Get("product")
+
+	license, err := h.service.GetLicense(key, product)
+	if err != nil {
+		http.Error(w, "License not found", http.StatusNotFound)
+		return
+	}
+
+	activations, err := h.service.ListActivations(license)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]ActivationResponse, 0, len(activations))
+	for _, a := range activations {
+		response = append(response, ActivationResponse{
+			MachineID: a.MachineID,
+			FirstSeen: a.FirstSeen,
+			LastSeen:  a.LastSeen,
+			IP:        a.IP,
+			Hostname:  a.Hostname,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Revoke kills a license immediately, independent of its expires_at.
+// Mounted behind admin authentication in main.go; the acting admin's
+// identity must already be attached to the request context via WithActor
+// so it lands in the audit log.
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RevokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RevokeLicense(req.Key, req.Product, req.Reason, actorFromContext(r.Context())); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RevokeResponse{Revoked: true})
+}
+
+// GetRevocations serves the revocation list for client-side CRL polling:
+// GET /license/revocations.json?since=<unix_seconds> returns every
+// revocation after since. Since the list only ever grows, the latest
+// revocation's timestamp doubles as an ETag/Last-Modified, so a poller
+// that has nothing new gets a cheap 304 instead of the full list.
+func (h *Handler) GetRevocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	latest, err := h.service.LatestRevocationAt()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !latest.IsZero() {
+		etag := `"` + strconv.FormatInt(latest.UnixNano(), 10) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", latest.UTC().Format(http.TimeFormat))
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+			if since, err := http.ParseTime(ims); err == nil && !latest.After(since) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+
+	revocations, err := h.service.ListRevocationsSince(parseSince(r.URL.Query().Get("since")))
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]RevocationEntry, 0, len(revocations))
+	for _, rev := range revocations {
+		response = append(response, RevocationEntry{
+			Key:       rev.LicenseKey,
+			Product:   rev.Product,
+			RevokedAt: rev.RevokedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseSince parses the CRL endpoint's ?since= query parameter (unix
+// seconds), defaulting to the zero Time (everything) for a missing or
+// malformed value.
+func parseSince(raw string) time.Time {
+	if raw == "" {
+		return time.Time{}
+	}
+	sec, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// GetEntitlements serves GET /api/entitlements?product=<product>: the
+// resolved, cached view of every entitlement active for product, so a
+// caller never has to re-derive seat counts or expiry grace logic itself.
+func (h *Handler) GetEntitlements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	product := r.URL.Query().Get("product")
+	if product == "" {
+		http.Error(w, "Missing product", http.StatusBadRequest)
+		return
+	}
+
+	resolved, err := h.service.ResolveEntitlements(product)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resolved)
+}
+
+// UploadLicense accepts a signed license artifact (multipart form field
+// "license") in place of a typed-in key: POST /api/license, mounted
+// behind admin authentication in web.SetupRoutes. On success it responds
+// with the license row the artifact produced.
+func (h *Handler) UploadLicense(w http.ResponseWriter, r *http.Request) {
+	if h.artifactKeys == nil {
+		http.Error(w, "License upload not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxArtifactBytes); err != nil {
+		http.Error(w, "Invalid upload", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("license")
+	if err != nil {
+		http.Error(w, "Missing license field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(file, maxArtifactBytes))
+	if err != nil {
+		http.Error(w, "Invalid upload", http.StatusBadRequest)
+		return
+	}
+
+	lic, err := h.service.UploadLicense(h.artifactKeys, string(raw))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMalformedArtifact), errors.Is(err, ErrUnknownSigningKey),
+			errors.Is(err, ErrUnsupportedAlg), errors.Is(err, ErrInvalidSignature),
+			errors.Is(err, ErrInvalidEmail):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, database.ErrDuplicateKey):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UploadLicenseResponse{ID: lic.ID, Key: lic.Key, Product: lic.Product})
+}
+
+// ExportBundle streams a signed license migration bundle: GET
+// /api/license/export, mounted behind admin authentication in
+// web.SetupRoutes. See Service.ExportBundle for the bundle's format.
+func (h *Handler) ExportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.ticketSigner == nil {
+		http.Error(w, "License bundle export not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="license-bundle.tar.gz"`)
+	if err := h.service.ExportBundle(h.ticketSigner, w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ImportBundle accepts a signed license migration bundle (multipart form
+// field "bundle", see Service.ExportBundle) in place of typed-in license
+// details: POST /api/license/import, mounted behind admin authentication
+// in web.SetupRoutes. Every license in the bundle goes through the same
+// AddLicense path UploadLicense uses, so a duplicate key surfaces
+// database.ErrDuplicateKey exactly as it would there.
+func (h *Handler) ImportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.ticketSigner == nil {
+		http.Error(w, "License bundle import not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxBundleBytes); err != nil {
+		http.Error(w, "Invalid upload", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("bundle")
+	if err != nil {
+		http.Error(w, "Missing bundle field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	imported, err := h.service.ImportBundle(h.ticketSigner, io.LimitReader(file, maxBundleBytes))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMalformedBundle), errors.Is(err, ErrBundleSignatureInvalid),
+			errors.Is(err, ErrInvalidLicenseKey), errors.Is(err, ErrForgedLicenseKey), errors.Is(err, ErrInvalidEmail):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, database.ErrDuplicateKey):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportBundleResponse{Imported: imported})
+}
+
+// ActivateTicket issues a device-bound offline activation ticket for an
+// existing license: POST /api/license/activate. The caller's downstream
+// client verifies the returned ticket locally via pkg/ticket.Verify, with
+// no further server round trip.
+func (h *Handler) ActivateTicket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.ticketSigner == nil {
+		http.Error(w, "Ticket activation not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req ActivateTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	t, err := h.service.IssueActivationTicket(h.ticketSigner, req.LicenseKey, req.Product, req.DeviceFingerprint, 0)
+	if err != nil {
+		if errors.Is(err, database.ErrLicenseNotFound) {
+			http.Error(w, "License not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ActivateTicketResponse{Ticket: t})
+}
+
+// RevokeActivationTicket revokes a single activation ticket by its
+// pkg/ticket.ID without affecting the license it was issued from: POST
+// /api/license/tickets/{id}/revoke, mounted behind admin authentication in
+// web.SetupRoutes.
+func (h *Handler) RevokeActivationTicket(w http.ResponseWriter, r *http.Request) {
+	ticketID := chi.URLParam(r, "id")
+
+	if err := h.service.RevokeActivationTicket(ticketID); err != nil {
+		if errors.Is(err, database.ErrTicketNotFound) {
+			http.Error(w, "Ticket not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteLicenseByID deletes the license identified by the {id} path
+// segment: DELETE /api/license/{id}, mounted behind admin authentication
+// in web.SetupRoutes.
+func (h *Handler) DeleteLicenseByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteLicenseByID(id); err != nil {
+		if errors.Is(err, database.ErrLicenseNotFound) {
+			http.Error(w, "License not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func clientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		return xff
+	}
+	return r.RemoteAddr
+}
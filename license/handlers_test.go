@@ -0,0 +1,32 @@
+package license
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	if got := parseSince(""); !got.IsZero() {
+		t.Fatalf("expected zero Time for empty since, got %v", got)
+	}
+	if got := parseSince("not-a-number"); !got.IsZero() {
+		t.Fatalf("expected zero Time for malformed since, got %v", got)
+	}
+
+	want := time.Unix(1700000000, 0)
+	if got := parseSince("1700000000"); !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestActorFromContext(t *testing.T) {
+	if got := actorFromContext(context.Background()); got != "unknown" {
+		t.Fatalf("expected \"unknown\" for a context with no actor, got %q", got)
+	}
+
+	ctx := WithActor(context.Background(), "alice")
+	if got := actorFromContext(ctx); got != "alice" {
+		t.Fatalf("expected \"alice\", got %q", got)
+	}
+}
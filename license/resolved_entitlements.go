@@ -0,0 +1,193 @@
+package license
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// entitlementsGracePeriod is how long an expired license still counts
+// towards a product's ResolvedEntitlements, surfaced as a warning instead
+// of silently dropping a customer's access the instant their renewal is
+// late.
+const entitlementsGracePeriod = 30 * 24 * time.Hour
+
+// entitlementsExpiryWarningWindow is how far out from expiry a still-valid
+// license starts generating a "expires soon" warning.
+const entitlementsExpiryWarningWindow = 7 * 24 * time.Hour
+
+// ResolvedEntitlements is the aggregated entitlements view across every
+// license for one product: the union of enabled features, the combined
+// seat count and how many are in use, the earliest expiry among
+// still-counted licenses, and a warnings/errors split a caller can render
+// directly. This mirrors Coder's entitlements refactor, where downstream
+// code only ever sees a resolved struct instead of re-deriving seat/grace
+// logic itself.
+type ResolvedEntitlements struct {
+	Product        string     `json:"product"`
+	Features       []string   `json:"features,omitempty"`
+	SeatsTotal     int        `json:"seats_total,omitempty"`
+	SeatsUsed      int        `json:"seats_used,omitempty"`
+	EarliestExpiry *time.Time `json:"earliest_expiry,omitempty"`
+	Warnings       []string   `json:"warnings,omitempty"`
+	Errors         []string   `json:"errors,omitempty"`
+	ResolvedAt     time.Time  `json:"resolved_at"`
+}
+
+// ResolveEntitlements returns product's cached ResolvedEntitlements,
+// computing and caching it synchronously on a cold cache (e.g. the first
+// request for a product before any ticker tick has run).
+func (s *Service) ResolveEntitlements(product string) (ResolvedEntitlements, error) {
+	if cached, ok := s.cachedResolved(product); ok {
+		return cached, nil
+	}
+
+	resolved, err := s.computeResolvedEntitlements(product)
+	if err != nil {
+		return ResolvedEntitlements{}, err
+	}
+	s.storeResolved(product, resolved)
+	return resolved, nil
+}
+
+// RefreshEntitlements recomputes ResolvedEntitlements for every product
+// currently in the cache. Called on a ticker by StartEntitlementsRefresh;
+// AddLicense/DeleteLicense additionally refresh their own product inline
+// so an admin change is visible immediately rather than waiting out the
+// interval.
+func (s *Service) RefreshEntitlements() {
+	for _, product := range s.cachedProducts() {
+		if resolved, err := s.computeResolvedEntitlements(product); err == nil {
+			s.storeResolved(product, resolved)
+		}
+	}
+}
+
+// StartEntitlementsRefresh launches a background goroutine that calls
+// RefreshEntitlements every interval, so GET /api/entitlements and other
+// hot-path callers of ResolveEntitlements only hit MySQL themselves for a
+// product not yet in the cache.
+// Returns a stop func; ctx cancellation also stops the goroutine.
+func (s *Service) StartEntitlementsRefresh(ctx context.Context, interval time.Duration) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.RefreshEntitlements()
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		select {
+		case <-stop:
+		default:
+			close(stop)
+		}
+	}
+}
+
+func (s *Service) cachedResolved(product string) (ResolvedEntitlements, bool) {
+	m, _ := s.resolved.Load().(map[string]ResolvedEntitlements)
+	re, ok := m[product]
+	return re, ok
+}
+
+func (s *Service) cachedProducts() []string {
+	m, _ := s.resolved.Load().(map[string]ResolvedEntitlements)
+	products := make([]string, 0, len(m))
+	for product := range m {
+		products = append(products, product)
+	}
+	return products
+}
+
+// storeResolved replaces the cache entry for product with resolved,
+// copy-on-write so concurrent readers of the previous map are never
+// mutated out from under them.
+func (s *Service) storeResolved(product string, resolved ResolvedEntitlements) {
+	old, _ := s.resolved.Load().(map[string]ResolvedEntitlements)
+	next := make(map[string]ResolvedEntitlements, len(old)+1)
+	for k, v := range old {
+		next[k] = v
+	}
+	next[product] = resolved
+	s.resolved.Store(next)
+}
+
+// refreshProduct recomputes and caches product's ResolvedEntitlements,
+// logging nothing on failure: a stale cache entry (or none at all,
+// leaving the next ResolveEntitlements call to compute it synchronously)
+// is preferable to AddLicense/DeleteLicense failing outright over an
+// entitlements-cache refresh.
+func (s *Service) refreshProduct(product string) {
+	if resolved, err := s.computeResolvedEntitlements(product); err == nil {
+		s.storeResolved(product, resolved)
+	}
+}
+
+func (s *Service) computeResolvedEntitlements(product string) (ResolvedEntitlements, error) {
+	licenses, err := s.db.ListLicenses(product)
+	if err != nil {
+		return ResolvedEntitlements{}, err
+	}
+
+	resolved := ResolvedEntitlements{Product: product, ResolvedAt: time.Now()}
+	featureSet := make(map[string]bool)
+	now := time.Now()
+
+	for _, lic := range licenses {
+		if rev, err := s.db.IsRevoked(lic.Key, product); err == nil && rev != nil {
+			resolved.Errors = append(resolved.Errors, fmt.Sprintf("license %s revoked: %s", lic.Key, rev.Reason))
+			continue
+		}
+
+		if lic.ExpiresAt != nil && now.After(*lic.ExpiresAt) {
+			expiredFor := now.Sub(*lic.ExpiresAt)
+			if expiredFor > entitlementsGracePeriod {
+				resolved.Errors = append(resolved.Errors, fmt.Sprintf(
+					"license %s expired on %s (past %d-day grace)",
+					lic.Key, lic.ExpiresAt.Format("2006-01-02"), int(entitlementsGracePeriod.Hours()/24)))
+				continue
+			}
+			resolved.Warnings = append(resolved.Warnings, fmt.Sprintf(
+				"license %s expired but within %d-day grace", lic.Key, int(entitlementsGracePeriod.Hours()/24)))
+		} else if lic.ExpiresAt != nil && lic.ExpiresAt.Sub(now) <= entitlementsExpiryWarningWindow {
+			resolved.Warnings = append(resolved.Warnings, fmt.Sprintf(
+				"license %s expires in %d days", lic.Key, int(lic.ExpiresAt.Sub(now).Hours()/24)+1))
+		}
+
+		entitlements, err := ParseEntitlements(lic.Features)
+		if err != nil {
+			resolved.Errors = append(resolved.Errors, fmt.Sprintf("license %s has unparseable entitlements: %v", lic.Key, err))
+			continue
+		}
+		for _, feature := range entitlements.FeatureList {
+			featureSet[feature] = true
+		}
+		if seats := entitlements.Limit("seats"); seats > 0 {
+			resolved.SeatsTotal += seats
+		}
+		if count, err := s.db.CountActivations(lic.ID); err == nil {
+			resolved.SeatsUsed += count
+		}
+		if lic.ExpiresAt != nil && (resolved.EarliestExpiry == nil || lic.ExpiresAt.Before(*resolved.EarliestExpiry)) {
+			resolved.EarliestExpiry = lic.ExpiresAt
+		}
+	}
+
+	resolved.Features = make([]string, 0, len(featureSet))
+	for feature := range featureSet {
+		resolved.Features = append(resolved.Features, feature)
+	}
+	sort.Strings(resolved.Features)
+
+	return resolved, nil
+}
@@ -0,0 +1,29 @@
+package license
+
+import "testing"
+
+func TestServiceResolvedEntitlementsCache(t *testing.T) {
+	s := &Service{}
+
+	if _, ok := s.cachedResolved("acme"); ok {
+		t.Fatal("expected no cache entry before any store")
+	}
+
+	s.storeResolved("acme", ResolvedEntitlements{Product: "acme", SeatsTotal: 5})
+	cached, ok := s.cachedResolved("acme")
+	if !ok || cached.SeatsTotal != 5 {
+		t.Fatalf("expected cached entry with SeatsTotal 5, got %+v (ok=%v)", cached, ok)
+	}
+
+	s.storeResolved("other", ResolvedEntitlements{Product: "other"})
+	products := s.cachedProducts()
+	if len(products) != 2 {
+		t.Fatalf("expected 2 cached products, got %v", products)
+	}
+
+	// storeResolved must not mutate a previously-returned snapshot.
+	s.storeResolved("acme", ResolvedEntitlements{Product: "acme", SeatsTotal: 9})
+	if cached.SeatsTotal != 5 {
+		t.Fatalf("expected earlier snapshot to stay at SeatsTotal 5, got %d", cached.SeatsTotal)
+	}
+}
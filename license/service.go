@@ -3,34 +3,198 @@ package license
 import (
 	"errors"
 	"license-server/database"
+	"license-server/pkg/ticket"
+	"license-server/utils"
 	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// defaultTicketValidity is how long an activation ticket is valid for
+// when Service.IssueActivationTicket's caller doesn't override it.
+const defaultTicketValidity = 30 * 24 * time.Hour
+
+// IssueToken builds and signs an offline license token for an existing
+// license row, then persists the signature and issuance timestamp on
+// that row so it's visible alongside the license's other details.
+func (s *Service) IssueToken(signer *Signer, key, product string, features []string, maxActivations int) (string, error) {
+	lic, err := s.GetLicense(key, product)
+	if err != nil {
+		return "", err
+	}
+
+	var expiresAt int64
+	if lic.ExpiresAt != nil {
+		expiresAt = lic.ExpiresAt.Unix()
+	}
+
+	claims := TokenClaims{
+		Key:            lic.Key,
+		Product:        lic.Product,
+		OwnerEmail:     lic.OwnerEmail,
+		OwnerName:      lic.OwnerName,
+		ExpiresAt:      expiresAt,
+		Features:       features,
+		MaxActivations: maxActivations,
+	}
+
+	token, err := signer.Sign(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signature := token[strings.LastIndexByte(token, '.')+1:]
+	if err := s.db.SetLicenseToken(key, product, signature, time.Now()); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
 type Service struct {
 	db *database.Database
+
+	// resolved caches ResolveEntitlements' results as a
+	// map[string]ResolvedEntitlements, keyed by product. See
+	// resolved_entitlements.go.
+	resolved atomic.Value
 }
 
 func NewService(db *database.Database) *Service {
 	return &Service{db: db}
 }
 
-func (s *Service) AddLicense(key, product, ownerEmail, ownerName string, expiresAt *time.Time) error {
+func (s *Service) AddLicense(key, product, ownerEmail, ownerName string, expiresAt *time.Time, maxActivations int, entitlements Entitlements) error {
 	if !isValidLicenseKey(key) {
 		return ErrInvalidLicenseKey
 	}
+	if !utils.QuickVerifyLicenseKey(key, product) {
+		return ErrForgedLicenseKey
+	}
 	if !isValidEmail(ownerEmail) {
 		return ErrInvalidEmail
 	}
-	return s.db.AddLicense(key, product, expiresAt, ownerEmail, ownerName)
+	features, err := entitlements.JSON()
+	if err != nil {
+		return err
+	}
+	if err := s.db.AddLicense(key, product, expiresAt, ownerEmail, ownerName, maxActivations, features); err != nil {
+		return err
+	}
+	s.refreshProduct(product)
+	return nil
+}
+
+// GetEntitlements returns the parsed entitlements for (key, product).
+func (s *Service) GetEntitlements(key, product string) (Entitlements, error) {
+	lic, err := s.GetLicense(key, product)
+	if err != nil {
+		return Entitlements{}, err
+	}
+	return ParseEntitlements(lic.Features)
+}
+
+// SetEntitlements replaces the entitlements for (key, product), recording
+// a diff of the feature-list and tier change in the audit log.
+func (s *Service) SetEntitlements(key, product string, updated Entitlements) error {
+	lic, err := s.GetLicense(key, product)
+	if err != nil {
+		return err
+	}
+	old, err := ParseEntitlements(lic.Features)
+	if err != nil {
+		return err
+	}
+
+	features, err := updated.JSON()
+	if err != nil {
+		return err
+	}
+
+	details := diffEntitlements(old, updated)
+	if details == "" {
+		details = "no change"
+	}
+
+	return s.db.SetLicenseFeatures(key, product, features, details)
 }
 
 func (s *Service) GetLicense(key, product string) (*database.License, error) {
 	return s.db.GetLicense(key, product)
 }
 
+// QuickVerify reports whether key is at least plausibly genuine for
+// product — its format and, if it's a self-describing key (see
+// utils.GenerateLicenseKey), its embedded checksum — without touching
+// MySQL. A caller (e.g. a rate limiter in front of VerifyLicense) uses
+// this to drop obviously forged keys before they ever reach the database.
+// A false here means the key is definitely bad; a true does not mean the
+// key exists.
+func (s *Service) QuickVerify(key, product string) bool {
+	return isValidLicenseKey(key) && utils.QuickVerifyLicenseKey(key, product)
+}
+
+// GetLicenseByID looks up a license by its row ID, e.g. to resolve the
+// {id} segment of DELETE /api/license/{id}.
+func (s *Service) GetLicenseByID(id int) (*database.License, error) {
+	return s.db.GetLicenseByID(id)
+}
+
 func (s *Service) DeleteLicense(key, product string) error {
-	return s.db.DeleteLicense(key, product)
+	if err := s.db.DeleteLicense(key, product); err != nil {
+		return err
+	}
+	s.refreshProduct(product)
+	return nil
+}
+
+// DeleteLicenseByID deletes the license with the given row ID, the
+// counterpart lookup DELETE /api/license/{id} needs since the HTTP path
+// only carries an ID, not the (key, product) pair DeleteLicense expects.
+func (s *Service) DeleteLicenseByID(id int) error {
+	lic, err := s.db.GetLicenseByID(id)
+	if err != nil {
+		return err
+	}
+	return s.DeleteLicense(lic.Key, lic.Product)
+}
+
+// UploadLicense verifies a signed license artifact against keys, derives
+// a deterministic license key from it (see artifactLicenseKey), and
+// persists the parsed claims through the ordinary AddLicense path before
+// recording the original artifact in license_raw — so the audit log
+// shows exactly what was accepted, and a duplicate submission surfaces
+// database.ErrDuplicateKey like any other repeated AddLicense.
+func (s *Service) UploadLicense(keys *ArtifactKeySet, artifact string) (*database.License, error) {
+	claims, err := keys.Verify(artifact)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidEmail(claims.OwnerEmail) {
+		return nil, ErrInvalidEmail
+	}
+
+	var expiresAt *time.Time
+	if claims.ExpiresAt != 0 {
+		t := time.Unix(claims.ExpiresAt, 0)
+		expiresAt = &t
+	}
+
+	entitlements := Entitlements{FeatureList: claims.Features}
+	if claims.Seats > 0 {
+		entitlements.Limits = map[string]int{"seats": claims.Seats}
+	}
+
+	key := artifactLicenseKey(artifact)
+	if err := s.AddLicense(key, claims.Product, claims.OwnerEmail, claims.OwnerName, expiresAt, 0, entitlements); err != nil {
+		return nil, err
+	}
+	if err := s.db.SetLicenseRaw(key, claims.Product, artifact); err != nil {
+		return nil, err
+	}
+
+	return s.GetLicense(key, claims.Product)
 }
 
 func (s *Service) ListLicenses(product string) ([]database.License, error) {
@@ -41,9 +205,101 @@ func (s *Service) GetAuditLogs(limit int) ([]database.AuditLog, error) {
 	return s.db.GetAuditLogs(limit)
 }
 
+// RevokeLicense kills (key, product) immediately, independent of its
+// expires_at, recording actor (e.g. the admin username) in the audit log.
+func (s *Service) RevokeLicense(key, product, reason, actor string) error {
+	return s.db.RevokeLicense(key, product, reason, actor)
+}
+
+// UnrevokeLicense reverses a prior RevokeLicense.
+func (s *Service) UnrevokeLicense(key, product, actor string) error {
+	return s.db.UnrevokeLicense(key, product, actor)
+}
+
+// IsRevoked returns (key, product)'s revocation record, or nil if it
+// isn't revoked.
+func (s *Service) IsRevoked(key, product string) (*database.Revocation, error) {
+	return s.db.IsRevoked(key, product)
+}
+
+// ListRevocationsSince returns every revocation recorded after since, for
+// CRL pull distribution.
+func (s *Service) ListRevocationsSince(since time.Time) ([]database.Revocation, error) {
+	return s.db.ListRevocationsSince(since)
+}
+
+// LatestRevocationAt returns the most recent revocation timestamp across
+// every license, or the zero Time if none exist.
+func (s *Service) LatestRevocationAt() (time.Time, error) {
+	return s.db.LatestRevocationAt()
+}
+
+// ActivateMachine binds machineID to lic, rejecting the bind with
+// database.ErrMaxActivationsExceeded if lic.MaxActivations is set and
+// already reached by other machines.
+func (s *Service) ActivateMachine(lic *database.License, machineID, fingerprint, ip, hostname string) error {
+	return s.db.ActivateMachine(lic.ID, lic.Key, lic.Product, machineID, fingerprint, ip, hostname, lic.MaxActivations)
+}
+
+// DeactivateMachine frees machineID's activation slot on lic.
+func (s *Service) DeactivateMachine(lic *database.License, machineID string) error {
+	return s.db.DeactivateMachine(lic.ID, lic.Key, lic.Product, machineID)
+}
+
+// ListActivations returns every machine currently bound to lic.
+func (s *Service) ListActivations(lic *database.License) ([]database.MachineActivation, error) {
+	return s.db.ListActivations(lic.ID)
+}
+
+// IssueActivationTicket verifies (key, product) exists, then signs and
+// records a device-bound offline activation ticket for fingerprint good
+// for validFor (validFor <= 0 uses defaultTicketValidity). The returned
+// ticket is verified locally by a downstream client via
+// pkg/ticket.Verify against signer's public key — no further DB round
+// trip required per launch.
+func (s *Service) IssueActivationTicket(signer *Signer, key, product, fingerprint string, validFor time.Duration) (string, error) {
+	if validFor <= 0 {
+		validFor = defaultTicketValidity
+	}
+
+	lic, err := s.GetLicense(key, product)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := ticket.Issue(signer.priv, lic.ID, product, fingerprint, validFor)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := s.db.RecordActivationTicket(lic.ID, key, product, ticket.ID(t), ticket.HashFingerprint(fingerprint), now, now.Add(validFor)); err != nil {
+		return "", err
+	}
+
+	return t, nil
+}
+
+// ListActivationTickets returns every activation ticket issued for lic.
+func (s *Service) ListActivationTickets(lic *database.License) ([]database.ActivationTicket, error) {
+	return s.db.ListActivationTickets(lic.ID)
+}
+
+// RevokeActivationTicket marks a specific device's ticket revoked,
+// without touching the license it was issued from. See
+// database.ActivationTicket's doc comment for what this does and
+// doesn't guarantee.
+func (s *Service) RevokeActivationTicket(ticketID string) error {
+	return s.db.RevokeActivationTicket(ticketID)
+}
+
+// licenseKeyPattern accepts both the legacy 4-group key shape and the
+// self-describing 6-group PROD-XXXX-XXXX-XXXX-XXXX-CCCC shape
+// utils.GenerateLicenseKey produces.
+var licenseKeyPattern = regexp.MustCompile(`^[A-Z0-9]{4}(-[A-Z0-9]{4}){3}(-[A-Z0-9]{4}-[A-Z0-9]{4})?$`)
+
 func isValidLicenseKey(key string) bool {
-	match, _ := regexp.MatchString(`^[A-Z0-9]{4}-[A-Z0-9]{4}-[A-Z0-9]{4}-[A-Z0-9]{4}$`, key)
-	return match
+	return licenseKeyPattern.MatchString(key)
 }
 
 func isValidEmail(email string) bool {
@@ -53,5 +309,9 @@ func isValidEmail(email string) bool {
 
 var (
 	ErrInvalidLicenseKey = errors.New("invalid license key format")
-	ErrInvalidEmail     = errors.New("invalid email format")
-)
\ No newline at end of file
+	ErrInvalidEmail      = errors.New("invalid email format")
+	// ErrForgedLicenseKey is returned by AddLicense when key matches the
+	// self-describing key shape but its checksum group doesn't verify
+	// against product, per utils.QuickVerifyLicenseKey.
+	ErrForgedLicenseKey = errors.New("license key checksum does not match product")
+)
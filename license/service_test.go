@@ -0,0 +1,38 @@
+package license
+
+import (
+	"testing"
+
+	"license-server/utils"
+)
+
+// TestServiceQuickVerify exercises Service.QuickVerify directly, not just
+// the utils.QuickVerifyLicenseKey helper it wraps, since it's the layer
+// VerifyLicense actually calls to short-circuit obviously forged keys
+// before ever touching MySQL.
+func TestServiceQuickVerify(t *testing.T) {
+	s := &Service{}
+
+	key, err := utils.GenerateLicenseKey("acme", false)
+	if err != nil {
+		t.Fatalf("GenerateLicenseKey: %v", err)
+	}
+
+	if !s.QuickVerify(key, "acme") {
+		t.Fatalf("expected %q to quick-verify for product %q", key, "acme")
+	}
+	if s.QuickVerify(key, "other-product") {
+		t.Fatal("expected quick-verify to fail for a different product")
+	}
+	if s.QuickVerify("not-even-a-license-key", "acme") {
+		t.Fatal("expected a malformed key to fail quick-verify")
+	}
+
+	tampered := key[:len(key)-1] + "9"
+	if key[len(key)-1] == '9' {
+		tampered = key[:len(key)-1] + "8"
+	}
+	if s.QuickVerify(tampered, "acme") {
+		t.Fatalf("expected tampered checksum %q to fail quick-verify", tampered)
+	}
+}
@@ -0,0 +1,199 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultClockSkew is how far a Verifier lets a token's issued_at sit in
+// the future, or its expires_at sit in the past, before rejecting it —
+// guarding against the signing and verifying machines' clocks disagreeing
+// by a small amount rather than the token itself being stale.
+const defaultClockSkew = 2 * time.Minute
+
+// TokenClaims is the payload of a signed offline license token: enough
+// for a client to enforce a license without ever calling VerifyLicense.
+type TokenClaims struct {
+	Key            string   `json:"key"`
+	Product        string   `json:"product"`
+	OwnerEmail     string   `json:"owner_email"`
+	OwnerName      string   `json:"owner_name"`
+	IssuedAt       int64    `json:"issued_at"`
+	ExpiresAt      int64    `json:"expires_at,omitempty"`
+	Features       []string `json:"features,omitempty"`
+	MaxActivations int      `json:"max_activations,omitempty"`
+	Nonce          string   `json:"nonce"`
+}
+
+var (
+	ErrMalformedToken   = errors.New("malformed license token")
+	ErrInvalidSignature = errors.New("license token signature invalid")
+	ErrTokenExpired     = errors.New("license token expired")
+	ErrTokenNotYetValid = errors.New("license token not yet valid")
+)
+
+// Signer holds an Ed25519 private key and issues signed offline license
+// tokens from it, the same pattern Docker and Mattermost use for their
+// license files.
+type Signer struct {
+	priv ed25519.PrivateKey
+}
+
+// NewSigner wraps an already-loaded Ed25519 private key.
+func NewSigner(priv ed25519.PrivateKey) *Signer {
+	return &Signer{priv: priv}
+}
+
+// LoadSigner reads an Ed25519 private key previously written by
+// GenerateKeyFiles from path.
+func LoadSigner(path string) (*Signer, error) {
+	priv, err := readKey(path, ed25519.PrivateKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("signing key yüklenemedi: %w", err)
+	}
+	return NewSigner(ed25519.PrivateKey(priv)), nil
+}
+
+// PublicKey returns the public half of s's key, e.g. to build a Verifier
+// for the same server's own /license/verify-token endpoint.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.priv.Public().(ed25519.PublicKey)
+}
+
+// Sign builds a JWS-style token for claims: a base64url-encoded JSON
+// payload with a detached Ed25519 signature appended as
+// "<payload>.<signature>". IssuedAt and Nonce are filled in if unset.
+func (s *Signer) Sign(claims TokenClaims) (string, error) {
+	if claims.IssuedAt == 0 {
+		claims.IssuedAt = time.Now().Unix()
+	}
+	if claims.Nonce == "" {
+		nonce, err := randomNonce()
+		if err != nil {
+			return "", fmt.Errorf("nonce üretilemedi: %w", err)
+		}
+		claims.Nonce = nonce
+	}
+
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("claims serileştirilemedi: %w", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	signature := ed25519.Sign(s.priv, []byte(payload))
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verifier verifies signed offline license tokens against an Ed25519
+// public key entirely offline — no database or network round trip. A
+// downstream client embeds the server's public key as a constant; the
+// server itself derives one from its own Signer for /license/verify-token.
+type Verifier struct {
+	pub ed25519.PublicKey
+}
+
+// NewVerifier wraps an already-loaded Ed25519 public key.
+func NewVerifier(pub ed25519.PublicKey) *Verifier {
+	return &Verifier{pub: pub}
+}
+
+// LoadVerifier reads the Ed25519 public key written alongside a signing
+// key at path+".pub" by GenerateKeyFiles.
+func LoadVerifier(path string) (*Verifier, error) {
+	pub, err := readKey(path+".pub", ed25519.PublicKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("public key yüklenemedi: %w", err)
+	}
+	return NewVerifier(ed25519.PublicKey(pub)), nil
+}
+
+// Verify parses token, checks its signature against v's public key, and
+// checks expires_at/issued_at against time.Now() within defaultClockSkew.
+// It never touches the database: this is the whole point of an offline
+// token.
+func (v *Verifier) Verify(token string) (*TokenClaims, error) {
+	payload, sigPart, ok := strings.Cut(token, ".")
+	if !ok || payload == "" || sigPart == "" {
+		return nil, ErrMalformedToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if !ed25519.Verify(v.pub, []byte(payload), signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	var claims TokenClaims
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	now := time.Now()
+	if claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(defaultClockSkew)) {
+		return nil, ErrTokenNotYetValid
+	}
+	if claims.ExpiresAt != 0 && now.After(time.Unix(claims.ExpiresAt, 0).Add(defaultClockSkew)) {
+		return nil, ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+// GenerateKeyFiles generates a fresh Ed25519 keypair and writes the
+// private key to path (mode 0600) and the public key to path+".pub"
+// (mode 0644), both base64-std-encoded.
+func GenerateKeyFiles(path string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("anahtar üretilemedi: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(priv)), 0o600); err != nil {
+		return fmt.Errorf("private key yazılamadı: %w", err)
+	}
+	if err := os.WriteFile(path+".pub", []byte(base64.StdEncoding.EncodeToString(pub)), 0o644); err != nil {
+		return fmt.Errorf("public key yazılamadı: %w", err)
+	}
+	return nil
+}
+
+func readKey(path string, wantLen int) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("base64 çözülemedi: %w", err)
+	}
+	if len(key) != wantLen {
+		return nil, fmt.Errorf("beklenmeyen anahtar uzunluğu: %d (beklenen %d)", len(key), wantLen)
+	}
+	return key, nil
+}
+
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
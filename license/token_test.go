@@ -0,0 +1,162 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func generateSignerVerifier(t *testing.T) (*Signer, *Verifier) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	_ = pub
+	signer := NewSigner(priv)
+	return signer, NewVerifier(signer.PublicKey())
+}
+
+func TestSignerVerifierRoundTrip(t *testing.T) {
+	signer, verifier := generateSignerVerifier(t)
+
+	token, err := signer.Sign(TokenClaims{
+		Key:      "ABCD-EFGH-IJKL-MNOP",
+		Product:  "acme",
+		Features: []string{"sso", "audit-log"},
+	})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Key != "ABCD-EFGH-IJKL-MNOP" || claims.Product != "acme" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if claims.Nonce == "" {
+		t.Fatal("expected Sign to fill in a nonce")
+	}
+}
+
+func TestVerifierRejectsTamperedPayload(t *testing.T) {
+	signer, verifier := generateSignerVerifier(t)
+
+	token, err := signer.Sign(TokenClaims{Key: "ABCD-EFGH-IJKL-MNOP", Product: "acme"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		t.Fatal("expected a payload.signature token")
+	}
+	tampered := payload + "X." + sig
+
+	if _, err := verifier.Verify(tampered); err != ErrInvalidSignature {
+		t.Fatalf("Verify(tampered) = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifierRejectsWrongKey(t *testing.T) {
+	signer, _ := generateSignerVerifier(t)
+	_, otherVerifier := generateSignerVerifier(t)
+
+	token, err := signer.Sign(TokenClaims{Key: "ABCD-EFGH-IJKL-MNOP", Product: "acme"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if _, err := otherVerifier.Verify(token); err != ErrInvalidSignature {
+		t.Fatalf("Verify with wrong key = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifierRejectsMalformedToken(t *testing.T) {
+	_, verifier := generateSignerVerifier(t)
+
+	for _, tok := range []string{"", "no-dot-here", ".missing-payload", "missing-sig."} {
+		if _, err := verifier.Verify(tok); err != ErrMalformedToken {
+			t.Fatalf("Verify(%q) = %v, want %v", tok, err, ErrMalformedToken)
+		}
+	}
+}
+
+func TestVerifierRejectsExpiredToken(t *testing.T) {
+	signer, verifier := generateSignerVerifier(t)
+
+	token, err := signer.Sign(TokenClaims{
+		Key:       "ABCD-EFGH-IJKL-MNOP",
+		Product:   "acme",
+		ExpiresAt: time.Now().Add(-1 * time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if _, err := verifier.Verify(token); err != ErrTokenExpired {
+		t.Fatalf("Verify(expired) = %v, want %v", err, ErrTokenExpired)
+	}
+}
+
+func TestVerifierToleratesClockSkewAtExpiry(t *testing.T) {
+	signer, verifier := generateSignerVerifier(t)
+
+	token, err := signer.Sign(TokenClaims{
+		Key:       "ABCD-EFGH-IJKL-MNOP",
+		Product:   "acme",
+		ExpiresAt: time.Now().Add(-1 * time.Second).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if _, err := verifier.Verify(token); err != nil {
+		t.Fatalf("Verify just past expiry within skew = %v, want nil", err)
+	}
+}
+
+func TestVerifierRejectsTokenNotYetValid(t *testing.T) {
+	signer, verifier := generateSignerVerifier(t)
+
+	token, err := signer.Sign(TokenClaims{
+		Key:      "ABCD-EFGH-IJKL-MNOP",
+		Product:  "acme",
+		IssuedAt: time.Now().Add(1 * time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if _, err := verifier.Verify(token); err != ErrTokenNotYetValid {
+		t.Fatalf("Verify(not yet valid) = %v, want %v", err, ErrTokenNotYetValid)
+	}
+}
+
+func TestGenerateKeyFilesRoundTrip(t *testing.T) {
+	path := t.TempDir() + "/license-signing-key"
+	if err := GenerateKeyFiles(path); err != nil {
+		t.Fatalf("GenerateKeyFiles: %v", err)
+	}
+
+	signer, err := LoadSigner(path)
+	if err != nil {
+		t.Fatalf("LoadSigner: %v", err)
+	}
+	verifier, err := LoadVerifier(path)
+	if err != nil {
+		t.Fatalf("LoadVerifier: %v", err)
+	}
+
+	token, err := signer.Sign(TokenClaims{Key: "ABCD-EFGH-IJKL-MNOP", Product: "acme"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := verifier.Verify(token); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package license
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UploadClaims is the payload of a signed license artifact accepted by
+// Handler.UploadLicense: an operator uploads this file instead of typing
+// a plaintext key, and it's verified against a compiled-in public key set
+// rather than trusted at face value.
+type UploadClaims struct {
+	Product    string   `json:"product"`
+	ExpiresAt  int64    `json:"expires_at,omitempty"`
+	OwnerEmail string   `json:"owner_email"`
+	OwnerName  string   `json:"owner_name"`
+	Seats      int      `json:"seats,omitempty"`
+	Features   []string `json:"features,omitempty"`
+}
+
+// artifactHeader is the JWS-style header of a license artifact: which key
+// signed it (Kid) and with what algorithm.
+type artifactHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+var (
+	ErrMalformedArtifact = errors.New("malformed license artifact")
+	ErrUnknownSigningKey = errors.New("license artifact signed by unknown key")
+	ErrUnsupportedAlg    = errors.New("unsupported license artifact algorithm")
+)
+
+// ArtifactKeySet is the compiled-in set of public keys trusted to sign
+// license upload artifacts, indexed by the kid each was issued under.
+// There is deliberately no HS256 support: a shared secret able to verify
+// an artifact would also be able to mint one, so the server would have to
+// hold the same key the operator signs with.
+type ArtifactKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// NewArtifactKeySet builds an empty ArtifactKeySet; populate it with
+// AddEd25519/AddRSA or load one with LoadArtifactKeySet.
+func NewArtifactKeySet() *ArtifactKeySet {
+	return &ArtifactKeySet{keys: make(map[string]crypto.PublicKey)}
+}
+
+// AddEd25519 trusts pub to verify "EdDSA"-alg artifacts signed under kid.
+func (s *ArtifactKeySet) AddEd25519(kid string, pub ed25519.PublicKey) {
+	s.keys[kid] = pub
+}
+
+// AddRSA trusts pub to verify "RS256"-alg artifacts signed under kid.
+func (s *ArtifactKeySet) AddRSA(kid string, pub *rsa.PublicKey) {
+	s.keys[kid] = pub
+}
+
+// LoadArtifactKeySet reads every "<kid>.pub" file in dir into an
+// ArtifactKeySet: a 32-byte base64-std-encoded value (matching
+// GenerateKeyFiles' output) loads as an Ed25519 key, anything else is
+// parsed as a PEM-encoded PKIX RSA public key.
+func LoadArtifactKeySet(dir string) (*ArtifactKeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("artifact key directory okunamadı: %w", err)
+	}
+
+	keys := NewArtifactKeySet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pub") {
+			continue
+		}
+		kid := strings.TrimSuffix(name, ".pub")
+
+		raw, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("anahtar dosyası okunamadı (%s): %w", name, err)
+		}
+
+		if pub, ok := decodeEd25519Key(raw); ok {
+			keys.AddEd25519(kid, pub)
+			continue
+		}
+
+		pub, err := decodeRSAKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("anahtar ayrıştırılamadı (%s): %w", name, err)
+		}
+		keys.AddRSA(kid, pub)
+	}
+
+	return keys, nil
+}
+
+func decodeEd25519Key(raw []byte) (ed25519.PublicKey, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil || len(decoded) != ed25519.PublicKeySize {
+		return nil, false
+	}
+	return ed25519.PublicKey(decoded), true
+}
+
+func decodeRSAKey(raw []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("PEM bloğu bulunamadı")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("beklenmeyen anahtar türü: %T", pub)
+	}
+	return rsaPub, nil
+}
+
+// Verify parses artifact as a compact "<header>.<payload>.<signature>"
+// JWS, looks up its kid in s, and checks the signature with the
+// algorithm the header claims before returning the parsed claims.
+func (s *ArtifactKeySet) Verify(artifact string) (*UploadClaims, error) {
+	headerPart, payloadPart, sigPart, ok := splitArtifact(artifact)
+	if !ok {
+		return nil, ErrMalformedArtifact
+	}
+
+	headerRaw, err := base64.RawURLEncoding.DecodeString(headerPart)
+	if err != nil {
+		return nil, ErrMalformedArtifact
+	}
+	var header artifactHeader
+	if err := json.Unmarshal(headerRaw, &header); err != nil {
+		return nil, ErrMalformedArtifact
+	}
+
+	pub, ok := s.keys[header.Kid]
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return nil, ErrMalformedArtifact
+	}
+	signingInput := headerPart + "." + payloadPart
+
+	if err := verifySignature(header.Alg, pub, signingInput, signature); err != nil {
+		return nil, err
+	}
+
+	payloadRaw, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return nil, ErrMalformedArtifact
+	}
+	var claims UploadClaims
+	if err := json.Unmarshal(payloadRaw, &claims); err != nil {
+		return nil, ErrMalformedArtifact
+	}
+
+	return &claims, nil
+}
+
+func verifySignature(alg string, pub crypto.PublicKey, signingInput string, signature []byte) error {
+	switch alg {
+	case "EdDSA":
+		pk, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+		if !ed25519.Verify(pk, []byte(signingInput), signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "RS256":
+		pk, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+		sum := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(pk, crypto.SHA256, sum[:], signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return ErrUnsupportedAlg
+	}
+}
+
+func splitArtifact(artifact string) (header, payload, signature string, ok bool) {
+	parts := strings.Split(artifact, ".")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	if parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// SignArtifact builds a compact "<header>.<payload>.<signature>" license
+// artifact for claims, signed with priv under kid, for the slicense-signer
+// CLI to mint — the operator keeps priv, the server only ever sees the
+// public half via LoadArtifactKeySet.
+func SignArtifact(priv ed25519.PrivateKey, kid string, claims UploadClaims) (string, error) {
+	header, err := json.Marshal(artifactHeader{Alg: "EdDSA", Kid: kid})
+	if err != nil {
+		return "", fmt.Errorf("header serileştirilemedi: %w", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("claims serileştirilemedi: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature := ed25519.Sign(priv, []byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// artifactLicenseKey derives a stable license key from an artifact's
+// signing input (header+payload, ignoring the signature) so re-uploading
+// the same artifact hits the licenses table's (license_key, product)
+// unique constraint and surfaces database.ErrDuplicateKey instead of
+// silently creating a second row.
+func artifactLicenseKey(artifact string) string {
+	headerPart, payloadPart, _, ok := splitArtifact(artifact)
+	if !ok {
+		headerPart, payloadPart = artifact, ""
+	}
+	sum := sha256.Sum256([]byte(headerPart + "." + payloadPart))
+	hex := fmt.Sprintf("%X", sum[:8])
+	return fmt.Sprintf("%s-%s-%s-%s", hex[0:4], hex[4:8], hex[8:12], hex[12:16])
+}
@@ -0,0 +1,89 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+func generateArtifactKeySet(t *testing.T) (ed25519.PrivateKey, *ArtifactKeySet) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	keys := NewArtifactKeySet()
+	keys.AddEd25519("test-kid", pub)
+	return priv, keys
+}
+
+func TestArtifactSignVerifyRoundTrip(t *testing.T) {
+	priv, keys := generateArtifactKeySet(t)
+
+	artifact, err := SignArtifact(priv, "test-kid", UploadClaims{
+		Product:    "acme",
+		OwnerEmail: "owner@example.com",
+		OwnerName:  "Owner",
+		Seats:      5,
+		Features:   []string{"sso"},
+	})
+	if err != nil {
+		t.Fatalf("SignArtifact: %v", err)
+	}
+
+	claims, err := keys.Verify(artifact)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Product != "acme" || claims.Seats != 5 {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestArtifactVerifyUnknownKid(t *testing.T) {
+	priv, _ := generateArtifactKeySet(t)
+	keys := NewArtifactKeySet()
+
+	artifact, err := SignArtifact(priv, "test-kid", UploadClaims{Product: "acme"})
+	if err != nil {
+		t.Fatalf("SignArtifact: %v", err)
+	}
+
+	if _, err := keys.Verify(artifact); err != ErrUnknownSigningKey {
+		t.Fatalf("expected ErrUnknownSigningKey, got %v", err)
+	}
+}
+
+func TestArtifactVerifyTamperedPayload(t *testing.T) {
+	priv, keys := generateArtifactKeySet(t)
+
+	artifact, err := SignArtifact(priv, "test-kid", UploadClaims{Product: "acme"})
+	if err != nil {
+		t.Fatalf("SignArtifact: %v", err)
+	}
+
+	parts := strings.Split(artifact, ".")
+	tampered := parts[0] + "." + parts[1] + "X." + parts[2]
+
+	if _, err := keys.Verify(tampered); err == nil {
+		t.Fatal("expected tampered payload to fail verification")
+	}
+}
+
+func TestArtifactLicenseKeyStableAndFormatted(t *testing.T) {
+	priv, _ := generateArtifactKeySet(t)
+	artifact, err := SignArtifact(priv, "test-kid", UploadClaims{Product: "acme"})
+	if err != nil {
+		t.Fatalf("SignArtifact: %v", err)
+	}
+
+	key1 := artifactLicenseKey(artifact)
+	key2 := artifactLicenseKey(artifact)
+	if key1 != key2 {
+		t.Fatalf("expected artifactLicenseKey to be deterministic, got %q and %q", key1, key2)
+	}
+	if !isValidLicenseKey(key1) {
+		t.Fatalf("expected %q to match the license key format", key1)
+	}
+}
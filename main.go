@@ -1,25 +1,46 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/user"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
-	"database/sql"
 
 	"license-server/database"
+	"license-server/internal/audit"
+	"license-server/internal/jwtauth"
+	"license-server/internal/monitoring"
+	"license-server/internal/ratelimit"
 	"license-server/license"
 	"license-server/utils"
 	"license-server/web"
+	"license-server/web/middleware"
+	webservice "license-server/web/service"
 
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"github.com/urfave/cli/v2"
 )
 
+const configPath = "config.yml"
+
+// entitlementsRefreshInterval is how often license.Service recomputes its
+// cached ResolvedEntitlements for every product GET /api/entitlements has
+// been asked about.
+const entitlementsRefreshInterval = time.Minute
+
 func main() {
-	utils.LoadConfig("config.yml")
+	if err := utils.LoadConfig(configPath); err != nil {
+		log.Fatalf("Config yüklenemedi: %v", err)
+	}
 	app := &cli.App{
 		Name:  "license-server",
 		Usage: "Lisans yönetim sunucusu ve CLI aracı",
@@ -33,6 +54,11 @@ func main() {
 					&cli.StringFlag{Name: "email", Usage: "Sahibin e-posta adresi", Required: true},
 					&cli.StringFlag{Name: "name", Usage: "Sahibin adı", Required: true},
 					&cli.IntFlag{Name: "hours", Usage: "Lisans süresi saat cinsinden (opsiyonel)"},
+					&cli.IntFlag{Name: "max-activations", Usage: "İzin verilen azami aktivasyon sayısı (0 = sınırsız)"},
+					&cli.StringSliceFlag{Name: "features", Usage: "Lisansa eklenecek özellik (tekrarlanabilir)"},
+					&cli.StringFlag{Name: "tier", Usage: "Lisans tier'ı (örn. pro, enterprise)"},
+					&cli.StringSliceFlag{Name: "limit", Usage: "key=value biçiminde sayısal limit (tekrarlanabilir)"},
+					&cli.BoolFlag{Name: "crockford", Usage: "Rastgele anahtar üretilirken Base32 Crockford kodlamasını kullan"},
 				},
 				Action: handleAdd,
 			},
@@ -42,6 +68,21 @@ func main() {
 				ArgsUsage: "<key> <product>",
 				Action:    handleDelete,
 			},
+			{
+				Name:      "revoke",
+				Usage:     "Lisansı süresi dolmadan iptal et",
+				ArgsUsage: "<key> <product>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "reason", Usage: "İptal sebebi"},
+				},
+				Action: handleRevoke,
+			},
+			{
+				Name:      "unrevoke",
+				Usage:     "Bir lisansın iptalini geri al",
+				ArgsUsage: "<key> <product>",
+				Action:    handleUnrevoke,
+			},
 			{
 				Name:      "list",
 				Usage:     "Lisansları listele",
@@ -59,6 +100,61 @@ func main() {
 				Usage:  "HTTP sunucusunu başlat",
 				Action: handleServe,
 			},
+			{
+				Name:  "metrics",
+				Usage: "Çalışan sunucunun /metrics uç noktasını tablo halinde göster",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "url", Usage: "Metrik uç noktası", Value: "http://127.0.0.1:9090/metrics"},
+					&cli.StringFlag{Name: "format", Usage: "Çıktı biçimi: table|json|yaml", Value: "table"},
+					&cli.StringFlag{Name: "group", Usage: "Yalnızca bu grubu göster (http, license, users, db, cache, business)"},
+				},
+				Action: handleMetrics,
+			},
+			{
+				Name:      "issue",
+				Usage:     "Mevcut bir lisans için imzalı offline token üret",
+				ArgsUsage: "<key> <product>",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "feature", Usage: "Tokene eklenecek özellik (tekrarlanabilir)"},
+					&cli.IntFlag{Name: "max-activations", Usage: "İzin verilen azami aktivasyon sayısı (opsiyonel)"},
+				},
+				Action: handleIssue,
+			},
+			{
+				Name:      "keygen",
+				Usage:     "Offline token imzalamak için yeni bir Ed25519 anahtar çifti üret",
+				ArgsUsage: "<path>",
+				Action:    handleKeygen,
+			},
+			{
+				Name:      "deactivate",
+				Usage:     "Bir makinenin aktivasyon slotunu serbest bırak",
+				ArgsUsage: "<key> <product> <machine_id>",
+				Action:    handleDeactivate,
+			},
+			{
+				Name:      "activations",
+				Usage:     "Bir lisansa bağlı makineleri listele",
+				ArgsUsage: "<key> <product>",
+				Action:    handleActivations,
+			},
+			{
+				Name:      "entitlements",
+				Usage:     "Bir lisansın entitlement'larını göster",
+				ArgsUsage: "<key> <product>",
+				Action:    handleEntitlements,
+			},
+			{
+				Name:      "set-entitlements",
+				Usage:     "Bir lisansın entitlement'larını güncelle",
+				ArgsUsage: "<key> <product>",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "features", Usage: "Lisansın yeni özellik listesi (tekrarlanabilir)"},
+					&cli.StringFlag{Name: "tier", Usage: "Lisans tier'ı (örn. pro, enterprise)"},
+					&cli.StringSliceFlag{Name: "limit", Usage: "key=value biçiminde sayısal limit (tekrarlanabilir)"},
+				},
+				Action: handleSetEntitlements,
+			},
 		},
 	}
 
@@ -69,7 +165,7 @@ func main() {
 }
 
 func getService() (*license.Service, error) {
-	cfg := utils.AppConfig
+	cfg := utils.Current()
 
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%d)/%s?parseTime=true",
@@ -88,7 +184,7 @@ func getService() (*license.Service, error) {
 }
 
 func getDB() (*sql.DB, error) {
-	cfg := utils.AppConfig
+	cfg := utils.Current()
 
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%d)/%s?parseTime=true",
@@ -108,23 +204,36 @@ func handleAdd(c *cli.Context) error {
 		return err
 	}
 
+	product := c.String("product")
+	email := c.String("email")
+	name := c.String("name")
+
 	key := c.String("key")
 	if key == "random" {
-		key = utils.GenerateLicenseKey()
+		key, err = utils.GenerateLicenseKey(product, c.Bool("crockford"))
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
 		fmt.Println("Oluşturulan lisans anahtarı:", key)
 	}
 
-	product := c.String("product")
-	email := c.String("email")
-	name := c.String("name")
-
 	var expiresAt *time.Time
 	if h := c.Int("hours"); h > 0 {
 		exp := time.Now().Add(time.Duration(h) * time.Hour)
 		expiresAt = &exp
 	}
 
-	err = service.AddLicense(key, product, email, name, expiresAt)
+	limits, err := parseLimitFlags(c.StringSlice("limit"))
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	entitlements := license.Entitlements{
+		TierName:    c.String("tier"),
+		FeatureList: c.StringSlice("features"),
+		Limits:      limits,
+	}
+
+	err = service.AddLicense(key, product, email, name, expiresAt, c.Int("max-activations"), entitlements)
 	if err != nil {
 		return fmt.Errorf("Lisans eklenemedi: %w", err)
 	}
@@ -140,6 +249,208 @@ func handleAdd(c *cli.Context) error {
 	return nil
 }
 
+func handleIssue(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.Exit("Kullanım: issue <key> <product>", 1)
+	}
+
+	keyPath := utils.Current().License.SigningKeyPath
+	if keyPath == "" {
+		return cli.Exit("license.signing_key_path yapılandırılmamış", 1)
+	}
+	signer, err := license.LoadSigner(keyPath)
+	if err != nil {
+		return err
+	}
+
+	service, err := getService()
+	if err != nil {
+		return err
+	}
+
+	key := c.Args().Get(0)
+	product := c.Args().Get(1)
+
+	token, err := service.IssueToken(signer, key, product, c.StringSlice("feature"), c.Int("max-activations"))
+	if err != nil {
+		return fmt.Errorf("token üretilemedi: %w", err)
+	}
+
+	fmt.Println(token)
+	return nil
+}
+
+func handleKeygen(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return cli.Exit("Kullanım: keygen <path>", 1)
+	}
+
+	path := c.Args().Get(0)
+	if err := license.GenerateKeyFiles(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Anahtar çifti üretildi: %s (private), %s.pub (public)\n", path, path)
+	return nil
+}
+
+func handleDeactivate(c *cli.Context) error {
+	if c.NArg() < 3 {
+		return cli.Exit("Kullanım: deactivate <key> <product> <machine_id>", 1)
+	}
+
+	service, err := getService()
+	if err != nil {
+		return err
+	}
+
+	key := c.Args().Get(0)
+	product := c.Args().Get(1)
+	machineID := c.Args().Get(2)
+
+	lic, err := service.GetLicense(key, product)
+	if err != nil {
+		return fmt.Errorf("lisans bulunamadı: %w", err)
+	}
+
+	if err := service.DeactivateMachine(lic, machineID); err != nil {
+		return fmt.Errorf("makine devre dışı bırakılamadı: %w", err)
+	}
+
+	fmt.Printf("Makine devre dışı bırakıldı: %s (Lisans: %s, Ürün: %s)\n", machineID, key, product)
+	return nil
+}
+
+func handleActivations(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.Exit("Kullanım: activations <key> <product>", 1)
+	}
+
+	service, err := getService()
+	if err != nil {
+		return err
+	}
+
+	key := c.Args().Get(0)
+	product := c.Args().Get(1)
+
+	lic, err := service.GetLicense(key, product)
+	if err != nil {
+		return fmt.Errorf("lisans bulunamadı: %w", err)
+	}
+
+	activations, err := service.ListActivations(lic)
+	if err != nil {
+		return err
+	}
+
+	if len(activations) == 0 {
+		fmt.Println("Bu lisans için bağlı makine bulunamadı.")
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Makine ID", "IP", "Hostname", "İlk Görülme", "Son Görülme"})
+
+	for _, a := range activations {
+		t.AppendRow(table.Row{a.MachineID, a.IP, a.Hostname, a.FirstSeen.Format("2006-01-02 15:04"), a.LastSeen.Format("2006-01-02 15:04")})
+	}
+
+	t.Render()
+	return nil
+}
+
+func handleEntitlements(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.Exit("Kullanım: entitlements <key> <product>", 1)
+	}
+
+	service, err := getService()
+	if err != nil {
+		return err
+	}
+
+	key := c.Args().Get(0)
+	product := c.Args().Get(1)
+
+	entitlements, err := service.GetEntitlements(key, product)
+	if err != nil {
+		return fmt.Errorf("lisans bulunamadı: %w", err)
+	}
+
+	fmt.Printf("Tier: %s\n", displayOrNone(entitlements.Tier()))
+	fmt.Printf("Özellikler: %s\n", displayOrNone(strings.Join(entitlements.FeatureList, ", ")))
+	if len(entitlements.Limits) == 0 {
+		fmt.Println("Limitler: (yok)")
+	} else {
+		fmt.Println("Limitler:")
+		for name, value := range entitlements.Limits {
+			fmt.Printf("  %s: %d\n", name, value)
+		}
+	}
+
+	return nil
+}
+
+func handleSetEntitlements(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.Exit("Kullanım: set-entitlements <key> <product>", 1)
+	}
+
+	service, err := getService()
+	if err != nil {
+		return err
+	}
+
+	key := c.Args().Get(0)
+	product := c.Args().Get(1)
+
+	limits, err := parseLimitFlags(c.StringSlice("limit"))
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	entitlements := license.Entitlements{
+		TierName:    c.String("tier"),
+		FeatureList: c.StringSlice("features"),
+		Limits:      limits,
+	}
+
+	if err := service.SetEntitlements(key, product, entitlements); err != nil {
+		return fmt.Errorf("entitlements güncellenemedi: %w", err)
+	}
+
+	fmt.Printf("Entitlements güncellendi: %s (Ürün: %s)\n", key, product)
+	return nil
+}
+
+func parseLimitFlags(raw []string) (map[string]int, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	limits := make(map[string]int, len(raw))
+	for _, kv := range raw {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("geçersiz limit: %q (beklenen biçim: key=value)", kv)
+		}
+		value, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("geçersiz limit değeri: %q", kv)
+		}
+		limits[parts[0]] = value
+	}
+	return limits, nil
+}
+
+func displayOrNone(s string) string {
+	if s == "" {
+		return "(yok)"
+	}
+	return s
+}
+
 func handleDelete(c *cli.Context) error {
 	if c.NArg() < 2 {
 		return cli.Exit("Kullanım: delete <key> <product>", 1)
@@ -162,6 +473,58 @@ func handleDelete(c *cli.Context) error {
 	return nil
 }
 
+func handleRevoke(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.Exit("Kullanım: revoke <key> <product>", 1)
+	}
+
+	service, err := getService()
+	if err != nil {
+		return err
+	}
+
+	key := c.Args().Get(0)
+	product := c.Args().Get(1)
+
+	if err := service.RevokeLicense(key, product, c.String("reason"), cliActor()); err != nil {
+		return fmt.Errorf("lisans iptal edilemedi: %w", err)
+	}
+
+	fmt.Printf("Lisans iptal edildi: %s (Ürün: %s)\n", key, product)
+	return nil
+}
+
+func handleUnrevoke(c *cli.Context) error {
+	if c.NArg() < 2 {
+		return cli.Exit("Kullanım: unrevoke <key> <product>", 1)
+	}
+
+	service, err := getService()
+	if err != nil {
+		return err
+	}
+
+	key := c.Args().Get(0)
+	product := c.Args().Get(1)
+
+	if err := service.UnrevokeLicense(key, product, cliActor()); err != nil {
+		return fmt.Errorf("lisans iptali geri alınamadı: %w", err)
+	}
+
+	fmt.Printf("Lisans iptali geri alındı: %s (Ürün: %s)\n", key, product)
+	return nil
+}
+
+// cliActor identifies who ran a CLI admin command, for the same audit
+// Details field the HTTP /license/revoke endpoint fills in with the
+// authenticated admin's username.
+func cliActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
+
 func handleList(c *cli.Context) error {
 	if c.NArg() < 1 {
 		return cli.Exit("Kullanım: list <product>", 1)
@@ -250,15 +613,327 @@ func handleServe(c *cli.Context) error {
 		return err
 	}
 
-	licenseHandler := license.NewHandler(service)
-	webRouter := web.SetupRoutes(db)
+	registry := prometheus.NewRegistry()
+	metrics := monitoring.NewMetrics(registry)
+
+	if statsDB, err := getDB(); err == nil {
+		if err := monitoring.RegisterDBStatsCollector(registry, statsDB); err != nil {
+			log.Printf("db stats collector kaydedilemedi: %v", err)
+		}
+	}
+
+	logger, err := monitoring.NewLogger("license-server", "dev", monitoring.LogConfig{
+		Level:    monitoring.LogLevel(utils.Current().Logging.Level),
+		Format:   monitoring.LogFormat(utils.Current().Logging.Format),
+		Output:   utils.Current().Logging.Output,
+		FilePath: utils.Current().Logging.FilePath,
+		Export:   buildLogExportConfig(metrics),
+	})
+	if err != nil {
+		return fmt.Errorf("logger oluşturulamadı: %w", err)
+	}
+
+	if auditSink, err := buildAuditSink(db); err != nil {
+		log.Printf("audit sink oluşturulamadı: %v", err)
+	} else if auditSink != nil {
+		logger = logger.WithAuditSink(auditSink)
+		if utils.Current().Audit.Exporter.Enabled {
+			exporter := audit.NewExporter(auditSink, audit.ExporterConfig{
+				Mode:       audit.ExporterMode(utils.Current().Audit.Exporter.Mode),
+				HTTPURL:    utils.Current().Audit.Exporter.HTTPURL,
+				SyslogAddr: utils.Current().Audit.Exporter.SyslogAddr,
+				Interval:   utils.Current().Audit.ExporterInterval(),
+			})
+			go func() {
+				if err := exporter.Run(context.Background()); err != nil {
+					log.Printf("audit dışa aktarıcı durdu: %v", err)
+				}
+			}()
+		}
+	}
+
+	reproducerCfg := monitoring.DefaultReproducerConfig()
+	if utils.Current().Logging.Reproducer.MaxBodyBytes > 0 {
+		reproducerCfg.MaxBodyBytes = utils.Current().Logging.Reproducer.MaxBodyBytes
+	}
+	if len(utils.Current().Logging.Reproducer.HeaderDenylist) > 0 {
+		reproducerCfg.HeaderDenylist = utils.Current().Logging.Reproducer.HeaderDenylist
+	}
+
+	if keysDir := utils.Current().JWT.SigningKeysDir; keysDir != "" {
+		if keyProvider, err := utils.LoadFileKeyProvider(keysDir, utils.Current().JWT.KeyOverlapDuration()); err != nil {
+			log.Printf("JWT imzalama anahtarları yüklenemedi, geçici anahtar kullanılmaya devam edilecek: %v", err)
+		} else {
+			utils.SetKeyProvider(keyProvider)
+		}
+	}
+
+	licenseHandler := license.NewHandler(service, metrics)
+	if keyPath := utils.Current().License.SigningKeyPath; keyPath != "" {
+		if signer, err := license.LoadSigner(keyPath); err != nil {
+			log.Printf("license signing key yüklenemedi, /license/verify-token devre dışı: %v", err)
+		} else {
+			licenseHandler = licenseHandler.WithTokenVerifier(license.NewVerifier(signer.PublicKey()))
+			licenseHandler = licenseHandler.WithTicketSigner(signer)
+		}
+	}
+	if keysDir := utils.Current().License.ArtifactKeysDir; keysDir != "" {
+		if artifactKeys, err := license.LoadArtifactKeySet(keysDir); err != nil {
+			log.Printf("license artifact anahtarları yüklenemedi, /api/license devre dışı: %v", err)
+		} else {
+			licenseHandler = licenseHandler.WithArtifactKeys(artifactKeys)
+		}
+	}
+	defer service.StartEntitlementsRefresh(context.Background(), entitlementsRefreshInterval)()
+
+	lastSeenInterval := utils.Current().UserActivity.LastSeenInterval()
+	webRouter := web.SetupRoutes(db, metrics, lastSeenInterval, logger, reproducerCfg, configPath, licenseHandler)
+
+	authService := webservice.NewAuthService(db, metrics)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/license/verify", licenseHandler.VerifyLicense)
+	mux.HandleFunc("/license/verify-token", licenseHandler.VerifyToken)
+	mux.HandleFunc("/license/deactivate", licenseHandler.Deactivate)
+	mux.HandleFunc("/license/activations", licenseHandler.ListActivations)
 	mux.HandleFunc("/license/audit-logs", licenseHandler.GetAuditLogs)
+	mux.HandleFunc("/license/revocations.json", licenseHandler.GetRevocations)
+	mux.Handle("/license/revoke", middleware.JWTAuthMiddleware(middleware.RequireAdmin(adminActorMiddleware(authService)(http.HandlerFunc(licenseHandler.Revoke)))))
 	mux.Handle("/api/", webRouter)
+	mux.Handle("/.well-known/jwks.json", webRouter)
+
+	buildTopHandler := func(cfg *utils.Config) http.Handler {
+		var h http.Handler = mux
+		if cfg.RateLimit.Enabled {
+			h = rateLimitMiddleware(metrics)(h)
+		}
+		return h
+	}
+
+	top := newReloadableHandler(buildTopHandler(utils.Current()))
+
+	stopJWTAuth := configureJWTAuth(utils.Current())
+	utils.OnReload(func(cfg *utils.Config) {
+		top.Store(buildTopHandler(cfg))
+		if stopJWTAuth != nil {
+			stopJWTAuth()
+		}
+		stopJWTAuth = configureJWTAuth(cfg)
+	})
+
+	if stopWatch, err := utils.WatchConfig(configPath); err != nil {
+		log.Printf("config izleyici başlatılamadı: %v", err)
+	} else {
+		defer stopWatch()
+	}
+
+	if utils.Current().Metrics.Enabled {
+		metricsCfg := monitoring.ServerConfig{
+			Address:     utils.Current().Metrics.Address,
+			TLSCertFile: utils.Current().Metrics.TLSCertFile,
+			TLSKeyFile:  utils.Current().Metrics.TLSKeyFile,
+		}
+
+		go func() {
+			if err := monitoring.Serve(context.Background(), metricsCfg, registry); err != nil && err != http.ErrServerClosed {
+				log.Printf("metrics sunucusu durdu: %v", err)
+			}
+		}()
+	}
+
+	go metrics.StartMetricsUpdater(context.Background(), db)
 
-	port := utils.AppConfig.Server.Port
+	port := utils.Current().Server.Port
 	fmt.Printf("Sunucu http://localhost:%d adresinde çalışıyor\n", port)
-	return http.ListenAndServe(fmt.Sprintf(":%d", port), mux)
-}
\ No newline at end of file
+	return http.ListenAndServe(fmt.Sprintf(":%d", port), top)
+}
+
+// reloadableHandler lets a config reload swap the serving handler (e.g.
+// to rebuild the rate limiter with a new policy) without restarting the
+// listener or dropping in-flight requests.
+type reloadableHandler struct {
+	ptr atomic.Pointer[http.Handler]
+}
+
+func newReloadableHandler(h http.Handler) *reloadableHandler {
+	rh := &reloadableHandler{}
+	rh.Store(h)
+	return rh
+}
+
+func (rh *reloadableHandler) Store(h http.Handler) {
+	rh.ptr.Store(&h)
+}
+
+func (rh *reloadableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*rh.ptr.Load()).ServeHTTP(w, r)
+}
+
+// buildLogExportConfig translates utils.Current().Logging.Export into a
+// monitoring.ExportConfig, picking the transport named by Transport. An
+// unset or unrecognized Transport with Enabled left on is treated as
+// disabled, since there is nowhere to ship entries to.
+func buildLogExportConfig(metrics *monitoring.Metrics) monitoring.ExportConfig {
+	cfg := utils.Current().Logging.Export
+	if !cfg.Enabled {
+		return monitoring.ExportConfig{}
+	}
+
+	var transport monitoring.LogTransport
+	switch cfg.Transport {
+	case "loki":
+		transport = monitoring.NewLokiTransport(cfg.URL, map[string]string{"service": "license-server"})
+	case "elasticsearch":
+		transport = monitoring.NewElasticsearchTransport(cfg.URL, cfg.Index)
+	case "otlp":
+		transport = monitoring.NewOTLPTransport(cfg.URL, nil)
+	default:
+		log.Printf("bilinmeyen log export transport %q, log export devre dışı", cfg.Transport)
+		return monitoring.ExportConfig{}
+	}
+
+	return monitoring.ExportConfig{
+		Enabled:        true,
+		Transport:      transport,
+		BufferSize:     cfg.BufferSize,
+		BatchSize:      cfg.BatchSize,
+		FlushInterval:  cfg.IntervalDuration(5 * time.Second),
+		DroppedCounter: metrics.LogExportDroppedTotal,
+	}
+}
+
+// buildAuditSink creates the MySQL-backed audit.Sink used for
+// monitoring.Logger.LogAudit, mirroring to a local file as well when
+// utils.Current().Audit.FilePath is set.
+func buildAuditSink(db *sql.DB) (audit.Sink, error) {
+	mysqlSink, err := audit.NewMySQLSink(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if utils.Current().Audit.FilePath == "" {
+		return mysqlSink, nil
+	}
+
+	fileSink, err := audit.NewFileSink(utils.Current().Audit.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return audit.NewMultiSink(mysqlSink, func(_ audit.Sink, err error) {
+		log.Printf("audit dosya yansıtması başarısız oldu: %v", err)
+	}, fileSink), nil
+}
+
+// rateLimitMiddleware builds the rate limit middleware from
+// utils.Current().RateLimit: a memory-backed limiter by default, or a
+// Redis-backed one (shared across replicas) when backend is "redis". The
+// default policy applies to every route without a more specific entry in
+// the routes list.
+func rateLimitMiddleware(metrics *monitoring.Metrics) func(http.Handler) http.Handler {
+	cfg := utils.Current().RateLimit
+
+	defaultRPI := cfg.Default.RequestsPerInterval
+	if defaultRPI <= 0 {
+		defaultRPI = 100
+	}
+	defaultBurst := cfg.Default.Burst
+	if defaultBurst <= 0 {
+		defaultBurst = 20
+	}
+
+	registry := ratelimit.NewPolicyRegistry(ratelimit.Policy{
+		Name:                "default",
+		RequestsPerInterval: defaultRPI,
+		Interval:            cfg.Default.IntervalDuration(time.Minute),
+		Burst:               defaultBurst,
+	})
+
+	for _, route := range cfg.Routes {
+		rpi := route.RequestsPerInterval
+		if rpi <= 0 {
+			rpi = defaultRPI
+		}
+		burst := route.Burst
+		if burst <= 0 {
+			burst = defaultBurst
+		}
+		registry.Register(route.Path, ratelimit.Policy{
+			Name:                route.Path,
+			RequestsPerInterval: rpi,
+			Interval:            route.IntervalDuration(time.Minute),
+			Burst:               burst,
+		})
+	}
+
+	var limiter ratelimit.RateLimiter
+	if cfg.Backend == "redis" {
+		limiter = ratelimit.NewRedisLimiter(redis.NewClient(&redis.Options{Addr: cfg.RedisAddr}), "ratelimit")
+	} else {
+		limiter = ratelimit.NewMemoryLimiter(10000, 10*time.Minute)
+	}
+
+	return ratelimit.Middleware(limiter, registry, metrics)
+}
+
+// adminActorMiddleware resolves the authenticated admin's username (via
+// authService, from the user ID middleware.JWTAuthMiddleware put in the
+// request context) and attaches it to the context with license.WithActor,
+// so a license.Handler admin action records who actually did it instead
+// of just "an admin did something". Must run after
+// middleware.JWTAuthMiddleware/RequireAdmin.
+func adminActorMiddleware(authService *webservice.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor := "unknown admin"
+			if userID, ok := middleware.GetUserID(r); ok {
+				if acct, err := authService.GetByID(userID); err == nil {
+					actor = acct.Username
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(license.WithActor(r.Context(), actor)))
+		})
+	}
+}
+
+// configureJWTAuth builds the jwtauth.Verifier and jwtauth.RevocationChecker
+// described by cfg.JWT and installs them via middleware.ConfigureJWTAuth. With
+// no issuers configured, JWTAuthMiddleware keeps accepting only the server's
+// own EdDSA session tokens, same as before jwt.issuers existed. Returns a
+// stop func that halts any background JWKS refresh started, or nil if none
+// was started.
+func configureJWTAuth(cfg *utils.Config) (stop func()) {
+	verifier := jwtauth.Verifier(middleware.LocalVerifier)
+
+	var jwksVerifier *jwtauth.JWKSVerifier
+	if len(cfg.JWT.Issuers) > 0 {
+		trusts := make([]jwtauth.IssuerTrust, len(cfg.JWT.Issuers))
+		for i, issuer := range cfg.JWT.Issuers {
+			trusts[i] = jwtauth.IssuerTrust{Issuer: issuer.Issuer, JWKSURL: issuer.JWKSURL, Audience: issuer.Audience}
+		}
+		jwksVerifier = jwtauth.NewJWKSVerifier(trusts, cfg.JWT.RefreshInterval())
+		jwksVerifier.StartBackgroundRefresh(context.Background())
+		verifier = &jwtauth.MultiVerifier{Local: middleware.LocalVerifier, JWKS: jwksVerifier}
+	}
+
+	var revocation jwtauth.RevocationChecker
+	if cfg.JWT.Revocation.Enabled {
+		keyPrefix := cfg.JWT.Revocation.KeyPrefix
+		if keyPrefix == "" {
+			keyPrefix = "jwt_revoked"
+		}
+		cacheSize := cfg.JWT.Revocation.CacheSize
+		if cacheSize <= 0 {
+			cacheSize = 10000
+		}
+		redisChecker := jwtauth.NewRedisRevocationChecker(redis.NewClient(&redis.Options{Addr: cfg.JWT.Revocation.RedisAddr}), keyPrefix)
+		revocation = jwtauth.NewLRURevocationChecker(redisChecker, cacheSize, cfg.JWT.RevocationCacheTTL())
+	}
+
+	middleware.ConfigureJWTAuth(verifier, revocation)
+
+	if jwksVerifier == nil {
+		return nil
+	}
+	return jwksVerifier.Close
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// metricGroups maps a logical group name to the name prefixes that belong
+// to it, mirroring the sections in monitoring.Metrics.
+var metricGroups = map[string][]string{
+	"http":     {"http_"},
+	"license":  {"license_"},
+	"users":    {"user_"},
+	"db":       {"db_", "database_"},
+	"cache":    {"cache_"},
+	"business": {"active_licenses", "expired_licenses", "active_users", "audit_logs"},
+}
+
+func groupForMetric(name string) string {
+	for group, prefixes := range metricGroups {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return group
+			}
+		}
+	}
+	return "other"
+}
+
+// metricRow is a flattened, aggregated view of one label combination of a
+// metric family, suitable for table/json/yaml rendering.
+type metricRow struct {
+	Metric string            `json:"metric" yaml:"metric"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Value  float64           `json:"value" yaml:"value"`
+}
+
+func handleMetrics(c *cli.Context) error {
+	url := c.String("url")
+	format := c.String("format")
+	group := c.String("group")
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("metrikler alınamadı: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("metrik sunucusu %d döndürdü", resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return fmt.Errorf("metrikler çözümlenemedi: %w", err)
+	}
+
+	rowsByGroup := make(map[string][]metricRow)
+	for name, mf := range families {
+		g := groupForMetric(name)
+		if group != "" && group != g {
+			continue
+		}
+		rowsByGroup[g] = append(rowsByGroup[g], familyToRows(name, mf)...)
+	}
+
+	groups := make([]string, 0, len(rowsByGroup))
+	for g := range rowsByGroup {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	switch format {
+	case "json", "yaml":
+		return renderStructured(groups, rowsByGroup, format)
+	default:
+		return renderTables(groups, rowsByGroup)
+	}
+}
+
+func familyToRows(name string, mf *dto.MetricFamily) []metricRow {
+	rows := make([]metricRow, 0, len(mf.Metric))
+	for _, m := range mf.Metric {
+		labels := make(map[string]string, len(m.Label))
+		for _, l := range m.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+
+		var value float64
+		switch {
+		case m.Counter != nil:
+			value = m.Counter.GetValue()
+		case m.Gauge != nil:
+			value = m.Gauge.GetValue()
+		case m.Histogram != nil:
+			value = float64(m.Histogram.GetSampleCount())
+		case m.Summary != nil:
+			value = float64(m.Summary.GetSampleCount())
+		case m.Untyped != nil:
+			value = m.Untyped.GetValue()
+		}
+
+		rows = append(rows, metricRow{Metric: name, Labels: labels, Value: value})
+	}
+	return rows
+}
+
+func renderTables(groups []string, rowsByGroup map[string][]metricRow) error {
+	for _, g := range groups {
+		fmt.Printf("\n%s\n", strings.ToUpper(g))
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Metrik", "Etiketler", "Değer"})
+
+		rows := rowsByGroup[g]
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Metric < rows[j].Metric })
+		for _, r := range rows {
+			t.AppendRow(table.Row{r.Metric, formatLabels(r.Labels), r.Value})
+		}
+		t.Render()
+	}
+	return nil
+}
+
+func renderStructured(groups []string, rowsByGroup map[string][]metricRow, format string) error {
+	out := make(map[string][]metricRow, len(groups))
+	for _, g := range groups {
+		out[g] = rowsByGroup[g]
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, labels[k]))
+	}
+	return strings.Join(parts, ", ")
+}
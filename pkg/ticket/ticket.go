@@ -0,0 +1,127 @@
+// Package ticket implements device-bound offline activation tickets: a
+// compact Ed25519-signed artifact a license server issues once per
+// device and a downstream Go client then verifies locally on every
+// subsequent launch, the same no-call-home guarantee license.Verifier
+// gives signed offline license tokens. The server's signing key is the
+// only trust root; Verify never makes a network or database call.
+package ticket
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Claims is the payload of an activation ticket.
+type Claims struct {
+	LicenseID       int    `json:"license_id"`
+	Product         string `json:"product"`
+	FingerprintHash string `json:"fingerprint_hash"`
+	IssuedAt        int64  `json:"issued_at"`
+	NotAfter        int64  `json:"not_after"`
+	Nonce           string `json:"nonce"`
+}
+
+var (
+	ErrMalformedTicket     = errors.New("malformed activation ticket")
+	ErrInvalidSignature    = errors.New("activation ticket signature invalid")
+	ErrTicketExpired       = errors.New("activation ticket expired")
+	ErrFingerprintMismatch = errors.New("activation ticket fingerprint mismatch")
+)
+
+// HashFingerprint hashes a client-supplied device fingerprint with
+// SHA-256, so neither the ticket itself nor the server's activation
+// records need to carry the raw value.
+func HashFingerprint(fingerprint string) string {
+	sum := sha256.Sum256([]byte(fingerprint))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue builds and signs an activation ticket binding licenseID/product to
+// fingerprint, valid from now until now+validFor.
+func Issue(priv ed25519.PrivateKey, licenseID int, product, fingerprint string, validFor time.Duration) (string, error) {
+	nonce, err := randomNonce()
+	if err != nil {
+		return "", fmt.Errorf("nonce generation failed: %w", err)
+	}
+
+	now := time.Now()
+	claims := Claims{
+		LicenseID:       licenseID,
+		Product:         product,
+		FingerprintHash: HashFingerprint(fingerprint),
+		IssuedAt:        now.Unix(),
+		NotAfter:        now.Add(validFor).Unix(),
+		Nonce:           nonce,
+	}
+
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("claims marshal failed: %w", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	signature := ed25519.Sign(priv, []byte(payload))
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks ticket's signature against pub, that it hasn't passed its
+// NotAfter, and that fingerprint hashes to the value the ticket was
+// issued for. It never touches the network or a database.
+func Verify(pub ed25519.PublicKey, ticket, fingerprint string) (*Claims, error) {
+	payload, sigPart, ok := strings.Cut(ticket, ".")
+	if !ok || payload == "" || sigPart == "" {
+		return nil, ErrMalformedTicket
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return nil, ErrMalformedTicket
+	}
+	if !ed25519.Verify(pub, []byte(payload), signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrMalformedTicket
+	}
+	var claims Claims
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, ErrMalformedTicket
+	}
+
+	if time.Now().Unix() > claims.NotAfter {
+		return nil, ErrTicketExpired
+	}
+	if HashFingerprint(fingerprint) != claims.FingerprintHash {
+		return nil, ErrFingerprintMismatch
+	}
+
+	return &claims, nil
+}
+
+// ID returns a stable, short identifier for ticket — derived from its
+// payload, not its signature — suitable for recording in an activations
+// table or audit log without re-deriving the full Claims.
+func ID(ticket string) string {
+	payload, _, _ := strings.Cut(ticket, ".")
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:8])
+}
+
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,72 @@
+package ticket
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+func generateKeypair(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	return pub, priv
+}
+
+func TestIssueVerifyRoundTrip(t *testing.T) {
+	pub, priv := generateKeypair(t)
+
+	ticketStr, err := Issue(priv, 42, "acme", "device-fingerprint", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	claims, err := Verify(pub, ticketStr, "device-fingerprint")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.LicenseID != 42 || claims.Product != "acme" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyRejectsWrongFingerprint(t *testing.T) {
+	pub, priv := generateKeypair(t)
+
+	ticketStr, err := Issue(priv, 1, "acme", "device-a", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if _, err := Verify(pub, ticketStr, "device-b"); err != ErrFingerprintMismatch {
+		t.Fatalf("expected ErrFingerprintMismatch, got %v", err)
+	}
+}
+
+func TestVerifyRejectsExpiredTicket(t *testing.T) {
+	pub, priv := generateKeypair(t)
+
+	ticketStr, err := Issue(priv, 1, "acme", "device-a", -time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if _, err := Verify(pub, ticketStr, "device-a"); err != ErrTicketExpired {
+		t.Fatalf("expected ErrTicketExpired, got %v", err)
+	}
+}
+
+func TestIDStableForSameTicket(t *testing.T) {
+	_, priv := generateKeypair(t)
+	ticketStr, err := Issue(priv, 1, "acme", "device-a", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if ID(ticketStr) != ID(ticketStr) {
+		t.Fatal("expected ID to be deterministic for the same ticket")
+	}
+}
@@ -1,12 +1,92 @@
 package utils
 
 import (
-	"log"
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// defaultLastSeenInterval is how often a valid JWT touches a user's
+// last_login timestamp when UserActivityConfig.Interval is unset or invalid.
+const defaultLastSeenInterval = 15 * time.Minute
+
+// UserActivityConfig controls the debounced "last seen" touch middleware.
+type UserActivityConfig struct {
+	Interval string `yaml:"last_seen_interval"`
+}
+
+// LastSeenInterval parses Interval, falling back to defaultLastSeenInterval
+// when it is empty or malformed.
+func (c UserActivityConfig) LastSeenInterval() time.Duration {
+	if c.Interval == "" {
+		return defaultLastSeenInterval
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return defaultLastSeenInterval
+	}
+	return d
+}
+
+// LogExportConfig is the yaml-decoded shape of Config.Logging.Export;
+// defined standalone (instead of inline) so it can carry a method.
+type LogExportConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Transport  string `yaml:"transport"`
+	URL        string `yaml:"url"`
+	Index      string `yaml:"index"`
+	Interval   string `yaml:"interval"`
+	BufferSize int    `yaml:"buffer_size"`
+	BatchSize  int    `yaml:"batch_size"`
+}
+
+// IntervalDuration parses Interval, falling back to def when it is empty
+// or malformed.
+func (c LogExportConfig) IntervalDuration(def time.Duration) time.Duration {
+	if c.Interval == "" {
+		return def
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// RateLimitRuleConfig configures one rate limit policy, either the default
+// or a per-path override.
+type RateLimitRuleConfig struct {
+	RequestsPerInterval int    `yaml:"requests_per_interval"`
+	Interval            string `yaml:"interval"`
+	Burst               int    `yaml:"burst"`
+}
+
+// IntervalDuration parses Interval, falling back to def when it is empty
+// or malformed.
+func (c RateLimitRuleConfig) IntervalDuration(def time.Duration) time.Duration {
+	if c.Interval == "" {
+		return def
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// RateLimitRouteConfig binds a RateLimitRuleConfig to an exact request
+// path, e.g. "/license/verify".
+type RateLimitRouteConfig struct {
+	Path                string `yaml:"path"`
+	RateLimitRuleConfig `yaml:",inline"`
+}
+
 type Config struct {
 	MySQL struct {
 		Host     string `yaml:"host"`
@@ -19,21 +99,385 @@ type Config struct {
 	Server struct {
 		Port int `yaml:"port"`
 	} `yaml:"server"`
+
+	Metrics struct {
+		Enabled     bool   `yaml:"enabled"`
+		Address     string `yaml:"address"`
+		TLSCertFile string `yaml:"tls_cert_file"`
+		TLSKeyFile  string `yaml:"tls_key_file"`
+	} `yaml:"metrics"`
+
+	UserActivity UserActivityConfig `yaml:"user_activity"`
+
+	Logging struct {
+		Level    string `yaml:"level"`
+		Format   string `yaml:"format"`
+		Output   string `yaml:"output"`
+		FilePath string `yaml:"file_path"`
+
+		Reproducer struct {
+			Enabled        bool     `yaml:"enabled"`
+			MaxBodyBytes   int64    `yaml:"max_body_bytes"`
+			HeaderDenylist []string `yaml:"header_denylist"`
+		} `yaml:"reproducer"`
+
+		Export LogExportConfig `yaml:"export"`
+	} `yaml:"logging"`
+
+	RateLimit struct {
+		Enabled   bool                   `yaml:"enabled"`
+		Backend   string                 `yaml:"backend"` // "memory" or "redis"
+		RedisAddr string                 `yaml:"redis_addr"`
+		Default   RateLimitRuleConfig    `yaml:"default"`
+		Routes    []RateLimitRouteConfig `yaml:"routes"`
+	} `yaml:"rate_limit"`
+
+	Audit AuditConfig `yaml:"audit"`
+
+	Admin AdminConfig `yaml:"admin"`
+
+	JWT JWTConfig `yaml:"jwt"`
+
+	License struct {
+		SigningKeyPath  string `yaml:"signing_key_path"`
+		ArtifactKeysDir string `yaml:"artifact_keys_dir"`
+	} `yaml:"license"`
+}
+
+// JWTIssuerConfig trusts one externally-hosted JWKS issuer (an internal
+// auth service, a partner IdP, ...) in addition to the server's own
+// EdDSA-signed session tokens.
+type JWTIssuerConfig struct {
+	Issuer   string `yaml:"issuer"`
+	JWKSURL  string `yaml:"jwks_url"`
+	Audience string `yaml:"audience"`
+}
+
+// JWTConfig controls JWKS-based verification of externally-issued tokens
+// and the optional Redis-backed revocation list checked by jti.
+type JWTConfig struct {
+	Issuers             []JWTIssuerConfig `yaml:"issuers"`
+	JWKSRefreshInterval string            `yaml:"jwks_refresh_interval"`
+
+	// SigningKeysDir, if set, makes the server's own session tokens
+	// EdDSA-signed with a durable, rotatable utils.FileKeyProvider
+	// instead of the process-local EphemeralKeyProvider. See
+	// GET /.well-known/jwks.json for the published verification keys.
+	SigningKeysDir string `yaml:"signing_keys_dir"`
+	// KeyOverlap is how long a retired signing kid keeps verifying
+	// already-issued tokens after a rotation, e.g. "24h". Defaults to 24h
+	// when empty or malformed.
+	KeyOverlap string `yaml:"key_overlap"`
+
+	Revocation struct {
+		Enabled   bool   `yaml:"enabled"`
+		RedisAddr string `yaml:"redis_addr"`
+		KeyPrefix string `yaml:"key_prefix"`
+		CacheSize int    `yaml:"cache_size"`
+		CacheTTL  string `yaml:"cache_ttl"`
+	} `yaml:"revocation"`
+}
+
+// RefreshInterval parses JWKSRefreshInterval, falling back to 10 minutes
+// when it is empty or malformed.
+func (c JWTConfig) RefreshInterval() time.Duration {
+	const def = 10 * time.Minute
+	if c.JWKSRefreshInterval == "" {
+		return def
+	}
+	d, err := time.ParseDuration(c.JWKSRefreshInterval)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// RevocationCacheTTL parses Revocation.CacheTTL, falling back to 30s when
+// it is empty or malformed.
+func (c JWTConfig) RevocationCacheTTL() time.Duration {
+	const def = 30 * time.Second
+	if c.Revocation.CacheTTL == "" {
+		return def
+	}
+	d, err := time.ParseDuration(c.Revocation.CacheTTL)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// KeyOverlapDuration parses KeyOverlap, falling back to 24h when it is
+// empty or malformed.
+func (c JWTConfig) KeyOverlapDuration() time.Duration {
+	const def = 24 * time.Hour
+	if c.KeyOverlap == "" {
+		return def
+	}
+	d, err := time.ParseDuration(c.KeyOverlap)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// AdminConfig lists the accounts allowed to call admin-only endpoints
+// (e.g. POST /api/admin/config/reload). There is no role column on
+// Account yet, so admin status is an allowlist of user IDs rather than a
+// database-backed role.
+type AdminConfig struct {
+	UserIDs []int `yaml:"user_ids"`
+}
+
+// IsAdmin reports whether userID is in the admin allowlist.
+func (c AdminConfig) IsAdmin(userID int) bool {
+	for _, id := range c.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditConfig controls the tamper-evident audit sink and its optional SIEM
+// exporter.
+type AuditConfig struct {
+	// FilePath, if set, mirrors every audit event to a local JSON-lines
+	// file in addition to the MySQL audit_chain table.
+	FilePath string `yaml:"file_path"`
+
+	Exporter struct {
+		Enabled    bool   `yaml:"enabled"`
+		Mode       string `yaml:"mode"` // "http" or "syslog"
+		HTTPURL    string `yaml:"http_url"`
+		SyslogAddr string `yaml:"syslog_addr"`
+		Interval   string `yaml:"interval"`
+	} `yaml:"exporter"`
 }
 
+// ExporterInterval parses Exporter.Interval, falling back to 30s when it
+// is empty or malformed.
+func (c AuditConfig) ExporterInterval() time.Duration {
+	const def = 30 * time.Second
+	if c.Exporter.Interval == "" {
+		return def
+	}
+	d, err := time.ParseDuration(c.Exporter.Interval)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// configPtr holds the active Config. Reads go through Current(), writes
+// through LoadConfig/ReloadConfig, so a concurrent reload never hands out
+// a half-decoded Config.
+var configPtr atomic.Pointer[Config]
+
+// AppConfig is kept for source compatibility with callers written before
+// hot-reload; it is only ever assigned in LoadConfig/ReloadConfig and
+// should be treated as a snapshot, not a live reference. New code should
+// call Current() instead, since it always reflects the latest reload.
 var AppConfig *Config
 
-func LoadConfig(path string) {
+// Current returns the active Config. It is safe to call from any
+// goroutine, including concurrently with a reload.
+func Current() *Config {
+	return configPtr.Load()
+}
+
+var (
+	reloadMu        sync.Mutex
+	reloadCallbacks []func(*Config)
+)
+
+// OnReload registers fn to run, in registration order, after every
+// successful ReloadConfig call; LoadConfig runs none. Subsystems that cache
+// config-derived state (rate limiters, exporters, ...) use this to rebuild
+// themselves instead of reading Config only once at startup.
+func OnReload(fn func(*Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadCallbacks = append(reloadCallbacks, fn)
+}
+
+// LoadConfig decodes the YAML file at path, applies environment variable
+// overrides, validates the result, and—only if all three succeed—makes it
+// the active Config. It returns an error instead of exiting the process,
+// so its caller (main, at startup) can decide how to react to a bad
+// config; the /api/admin/config/reload handler and the file watcher go
+// through ReloadConfig instead.
+func LoadConfig(path string) error {
+	cfg, err := decodeConfig(path)
+	if err != nil {
+		return err
+	}
+
+	configPtr.Store(cfg)
+	AppConfig = cfg
+	return nil
+}
+
+// ReloadConfig re-decodes path and, if it validates, swaps it in and runs
+// every callback registered with OnReload. The previously active Config
+// keeps serving requests if decoding or validation fails.
+func ReloadConfig(path string) error {
+	cfg, err := decodeConfig(path)
+	if err != nil {
+		return err
+	}
+
+	configPtr.Store(cfg)
+	AppConfig = cfg
+
+	reloadMu.Lock()
+	callbacks := append([]func(*Config){}, reloadCallbacks...)
+	reloadMu.Unlock()
+	for _, fn := range callbacks {
+		fn(cfg)
+	}
+	return nil
+}
+
+func decodeConfig(path string) (*Config, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		log.Fatalf("Config dosyası açılamadı: %v", err)
+		return nil, fmt.Errorf("config dosyası açılamadı: %w", err)
 	}
 	defer file.Close()
 
-	decoder := yaml.NewDecoder(file)
-	AppConfig = &Config{}
-	err = decoder.Decode(AppConfig)
-	if err != nil {
-		log.Fatalf("Config dosyası çözümlenemedi: %v", err)
+	cfg := &Config{}
+	if err := yaml.NewDecoder(file).Decode(cfg); err != nil {
+		return nil, fmt.Errorf("config dosyası çözümlenemedi: %w", err)
+	}
+
+	applyEnvOverrides(cfg)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config doğrulanamadı: %w", err)
+	}
+	return cfg, nil
+}
+
+// applyEnvOverrides lets deployment environments override the handful of
+// settings that commonly differ per-environment (DB credentials, ports,
+// Redis address) without templating the YAML file itself. Unset or
+// unparsable variables are left to the YAML value.
+func applyEnvOverrides(cfg *Config) {
+	if v, ok := os.LookupEnv("SLICENSE_MYSQL_HOST"); ok {
+		cfg.MySQL.Host = v
+	}
+	if v, ok := os.LookupEnv("SLICENSE_MYSQL_PORT"); ok {
+		if port, err := strconv.Atoi(v); err == nil {
+			cfg.MySQL.Port = port
+		}
+	}
+	if v, ok := os.LookupEnv("SLICENSE_MYSQL_USER"); ok {
+		cfg.MySQL.User = v
+	}
+	if v, ok := os.LookupEnv("SLICENSE_MYSQL_PASSWORD"); ok {
+		cfg.MySQL.Password = v
+	}
+	if v, ok := os.LookupEnv("SLICENSE_MYSQL_DATABASE"); ok {
+		cfg.MySQL.Database = v
+	}
+	if v, ok := os.LookupEnv("SLICENSE_SERVER_PORT"); ok {
+		if port, err := strconv.Atoi(v); err == nil {
+			cfg.Server.Port = port
+		}
+	}
+	if v, ok := os.LookupEnv("SLICENSE_RATE_LIMIT_REDIS_ADDR"); ok {
+		cfg.RateLimit.RedisAddr = v
+	}
+}
+
+// Validate checks the required fields and cross-field invariants that
+// decoding alone can't catch: missing required values, out-of-range
+// ports, and malformed URLs for the endpoints the server dials out to.
+func (c *Config) Validate() error {
+	if c.MySQL.Host == "" {
+		return fmt.Errorf("mysql.host zorunludur")
+	}
+	if err := validatePort("mysql.port", c.MySQL.Port); err != nil {
+		return err
+	}
+	if c.MySQL.Database == "" {
+		return fmt.Errorf("mysql.database zorunludur")
+	}
+
+	if err := validatePort("server.port", c.Server.Port); err != nil {
+		return err
+	}
+
+	if c.RateLimit.Enabled {
+		switch c.RateLimit.Backend {
+		case "memory", "redis":
+		default:
+			return fmt.Errorf("rate_limit.backend \"memory\" veya \"redis\" olmalıdır, alınan: %q", c.RateLimit.Backend)
+		}
+		if c.RateLimit.Backend == "redis" && c.RateLimit.RedisAddr == "" {
+			return fmt.Errorf("rate_limit.redis_addr, backend \"redis\" iken zorunludur")
+		}
+	}
+
+	if c.Logging.Export.Enabled {
+		switch c.Logging.Export.Transport {
+		case "loki", "elasticsearch", "otlp":
+		default:
+			return fmt.Errorf("logging.export.transport \"loki\", \"elasticsearch\" veya \"otlp\" olmalıdır, alınan: %q", c.Logging.Export.Transport)
+		}
+		if err := validateURL("logging.export.url", c.Logging.Export.URL); err != nil {
+			return err
+		}
+	}
+
+	if c.Audit.Exporter.Enabled {
+		switch c.Audit.Exporter.Mode {
+		case "http":
+			if err := validateURL("audit.exporter.http_url", c.Audit.Exporter.HTTPURL); err != nil {
+				return err
+			}
+		case "syslog":
+			if c.Audit.Exporter.SyslogAddr == "" {
+				return fmt.Errorf("audit.exporter.syslog_addr, mode \"syslog\" iken zorunludur")
+			}
+		default:
+			return fmt.Errorf("audit.exporter.mode \"http\" veya \"syslog\" olmalıdır, alınan: %q", c.Audit.Exporter.Mode)
+		}
+	}
+
+	for i, issuer := range c.JWT.Issuers {
+		if issuer.Issuer == "" {
+			return fmt.Errorf("jwt.issuers[%d].issuer zorunludur", i)
+		}
+		if err := validateURL(fmt.Sprintf("jwt.issuers[%d].jwks_url", i), issuer.JWKSURL); err != nil {
+			return err
+		}
+		if issuer.Audience == "" {
+			return fmt.Errorf("jwt.issuers[%d].audience zorunludur", i)
+		}
+	}
+
+	if c.JWT.Revocation.Enabled && c.JWT.Revocation.RedisAddr == "" {
+		return fmt.Errorf("jwt.revocation.redis_addr, revocation.enabled iken zorunludur")
+	}
+
+	return nil
+}
+
+func validatePort(field string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s 1-65535 aralığında olmalıdır, alınan: %d", field, port)
+	}
+	return nil
+}
+
+func validateURL(field, raw string) error {
+	if raw == "" {
+		return fmt.Errorf("%s zorunludur", field)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%s geçerli bir URL olmalıdır, alınan: %q", field, raw)
 	}
+	return nil
 }
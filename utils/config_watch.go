@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchConfig watches the directory containing path and calls
+// ReloadConfig(path) whenever the file is written or replaced (editors and
+// config-management tools commonly rewrite a file by renaming a temp file
+// over it, which fsnotify reports as Create on the watched directory, not
+// Write on the file itself—so the directory is what gets watched).
+//
+// It returns a stop function that closes the watcher; callers should defer
+// it for the lifetime of the process. A failed reload is logged and the
+// previously active Config keeps serving requests.
+func WatchConfig(path string) (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Name != path {
+					continue
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				if err := ReloadConfig(path); err != nil {
+					log.Printf("config yeniden yüklenemedi: %v", err)
+					continue
+				}
+				log.Printf("config yeniden yüklendi: %s", path)
+			case werr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("config izleyici hatası: %v", werr)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		watcher.Close()
+	}, nil
+}
@@ -1,36 +1,84 @@
 package utils
 
 import (
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("8394URNV893JFNCUW819SJDHUC8EISJCJHD72W8XDMJDJEJWIZIDMRM38W9D938284949WUCNHDEU")
-
 type Claims struct {
 	UserID int `json:"user_id"`
 	jwt.RegisteredClaims
 }
 
+// providerPtr holds the active KeyProvider GenerateJWT/ParseJWT sign and
+// verify against. It starts out an EphemeralKeyProvider so the package
+// never ships a secret anyone who reads the source could forge sessions
+// with; SetKeyProvider installs a durable one (see FileKeyProvider) once
+// config is loaded.
+var providerPtr atomic.Pointer[KeyProvider]
+
+func init() {
+	kp, err := NewEphemeralKeyProvider()
+	if err != nil {
+		panic(fmt.Sprintf("geçici JWT anahtarı üretilemedi: %v", err))
+	}
+	var provider KeyProvider = kp
+	providerPtr.Store(&provider)
+}
+
+// SetKeyProvider installs provider as the source of signing/verification
+// keys for GenerateJWT/ParseJWT and GET /.well-known/jwks.json. It is
+// safe to call from any goroutine; the swap takes effect for the next
+// token issued or parsed.
+func SetKeyProvider(provider KeyProvider) {
+	providerPtr.Store(&provider)
+}
+
+// CurrentKeyProvider returns the active KeyProvider, e.g. for the JWKS
+// handler and the key-rotation admin endpoint.
+func CurrentKeyProvider() KeyProvider {
+	return *providerPtr.Load()
+}
+
 func GenerateJWT(userID int) (string, error) {
+	return GenerateJWTWithTTL(userID, 24*time.Hour)
+}
+
+// GenerateJWTWithTTL issues an access token for userID that expires after ttl.
+// Used by the session subsystem to mint short-lived access tokens alongside
+// a longer-lived opaque refresh token.
+func GenerateJWTWithTTL(userID int, ttl time.Duration) (string, error) {
+	kid, priv, err := CurrentKeyProvider().SigningKey()
+	if err != nil {
+		return "", fmt.Errorf("imzalama anahtarı alınamadı: %w", err)
+	}
+
 	claims := &Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(priv)
 }
 
 func ParseJWT(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		pub, ok := CurrentKeyProvider().VerificationKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("bilinmeyen kid: %q", kid)
+		}
+		return pub, nil
+	}, jwt.WithValidMethods([]string{"EdDSA"}))
 	if err != nil || !token.Valid {
 		return nil, err
 	}
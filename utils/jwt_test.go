@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestGenerateJWTParseJWTRoundTrip(t *testing.T) {
+	token, err := GenerateJWT(42)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+
+	claims, err := ParseJWT(token)
+	if err != nil {
+		t.Fatalf("ParseJWT: %v", err)
+	}
+	if claims.UserID != 42 {
+		t.Fatalf("expected UserID 42, got %d", claims.UserID)
+	}
+}
+
+func TestParseJWTRejectsUnknownKid(t *testing.T) {
+	token, err := GenerateJWT(1)
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+
+	other, err := NewEphemeralKeyProvider()
+	if err != nil {
+		t.Fatalf("NewEphemeralKeyProvider: %v", err)
+	}
+	SetKeyProvider(other)
+	t.Cleanup(func() {
+		kp, _ := NewEphemeralKeyProvider()
+		SetKeyProvider(kp)
+	})
+
+	if _, err := ParseJWT(token); err == nil {
+		t.Fatal("expected ParseJWT to reject a token signed under a now-unknown kid")
+	}
+}
@@ -1,25 +1,154 @@
 package utils
 
 import (
-	"math/rand"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
 	"strings"
 	"time"
 )
 
-const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+// licenseKeySecretEnv is the environment variable holding the HMAC secret
+// mixed into every generated license key's checksum group. Set it in
+// production; the fallback in licenseKeySecret is for local/dev use only.
+const licenseKeySecretEnv = "SLICENSE_LICENSE_KEY_SECRET"
 
-func GenerateLicenseKey() string {
-	rand.Seed(time.Now().UnixNano())
-	var sb strings.Builder
+func licenseKeySecret() []byte {
+	if v := os.Getenv(licenseKeySecretEnv); v != "" {
+		return []byte(v)
+	}
+	return []byte("dev-only-license-key-secret-do-not-use-in-production")
+}
+
+const alphanumericAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// crockfordAlphabet is the Base32 Crockford alphabet: it drops the easily
+// confused I/L/O/U the standard RFC 4648 alphabet uses.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// dayEpoch returns the number of days since the Unix epoch, the
+// granularity GenerateLicenseKey/QuickVerifyLicenseKey embed as a key's
+// issue date.
+func dayEpoch(t time.Time) uint64 {
+	return uint64(t.Unix() / 86400)
+}
+
+// GenerateLicenseKey returns a cryptographically random, self-describing
+// license key of the form PROD-XXXX-XXXX-XXXX-XXXX-CCCC: a 4-character
+// product prefix, an issue-day group, two random groups, and a truncated
+// HMAC-SHA256 checksum over (product || payload) keyed by
+// licenseKeySecret. Because the issue day rides along in the key itself,
+// QuickVerifyLicenseKey can recompute and check the checksum without a
+// database round trip. crockford selects Base32 Crockford encoding for the
+// random groups instead of the default alphanumeric alphabet; it has no
+// effect on the product prefix or checksum group.
+func GenerateLicenseKey(product string, crockford bool) (string, error) {
+	alphabet := alphanumericAlphabet
+	if crockford {
+		alphabet = crockfordAlphabet
+	}
+
+	prefix := productPrefix(product)
 
-	for i := 0; i < 4; i++ {
-		if i > 0 {
-			sb.WriteString("-")
+	randomPart, err := randomGroupChars(12, alphabet)
+	if err != nil {
+		return "", fmt.Errorf("rastgele anahtar üretilemedi: %w", err)
+	}
+
+	dayGroup := encodeGroup(dayEpoch(time.Now()), alphabet)
+	payload := dayGroup + randomPart
+
+	checksum := checksumGroup(product, payload)
+
+	return fmt.Sprintf("%s-%s-%s-%s-%s-%s", prefix, payload[0:4], payload[4:8], payload[8:12], payload[12:16], checksum), nil
+}
+
+// productPrefix derives GenerateLicenseKey's leading group from product: its
+// first 4 alphanumeric characters, uppercased, right-padded with 'X' if
+// product is shorter than that.
+func productPrefix(product string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(product) {
+		if sb.Len() == 4 {
+			break
 		}
-		for j := 0; j < 4; j++ {
-			sb.WriteByte(chars[rand.Intn(len(chars))])
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
 		}
 	}
+	for sb.Len() < 4 {
+		sb.WriteByte('X')
+	}
+	return sb.String()
+}
+
+// randomGroupChars returns n cryptographically random characters drawn
+// from alphabet. It is safe for concurrent use: unlike the math/rand
+// generator it replaces, it keeps no seeded global state.
+func randomGroupChars(n int, alphabet string) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	out := make([]byte, n)
+	for i, b := range buf {
+		out[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return string(out), nil
+}
 
+// encodeGroup formats v as a 4-character group in alphabet, most
+// significant digit first.
+func encodeGroup(v uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	buf := make([]byte, 4)
+	for i := 3; i >= 0; i-- {
+		buf[i] = alphabet[v%base]
+		v /= base
+	}
+	return string(buf)
+}
+
+// checksumGroup computes GenerateLicenseKey's trailing checksum group: a
+// truncated, alphanumeric-encoded HMAC-SHA256 tag over product and payload,
+// keyed by licenseKeySecret.
+func checksumGroup(product, payload string) string {
+	mac := hmac.New(sha256.New, licenseKeySecret())
+	mac.Write([]byte(product))
+	mac.Write([]byte(payload))
+	sum := mac.Sum(nil)
+
+	var sb strings.Builder
+	for _, b := range sum[:4] {
+		sb.WriteByte(alphanumericAlphabet[int(b)%len(alphanumericAlphabet)])
+	}
 	return sb.String()
-}
\ No newline at end of file
+}
+
+// QuickVerifyLicenseKey reports whether key is a well-formed, self-describing
+// license key (see GenerateLicenseKey) whose checksum group matches product
+// — i.e. whether it's worth spending a database lookup on at all. A
+// manually-assigned or legacy-format key (anything not matching the
+// PROD-XXXX-XXXX-XXXX-XXXX-CCCC shape) isn't self-describing and always
+// passes, since there's nothing here to check it against.
+func QuickVerifyLicenseKey(key, product string) bool {
+	groups := strings.Split(key, "-")
+	if len(groups) != 6 {
+		return true
+	}
+	for _, g := range groups {
+		if len(g) != 4 {
+			return true
+		}
+	}
+
+	if groups[0] != productPrefix(product) {
+		return false
+	}
+
+	payload := groups[1] + groups[2] + groups[3] + groups[4]
+	return checksumGroup(product, payload) == groups[5]
+}
@@ -0,0 +1,53 @@
+package utils
+
+import "testing"
+
+func TestGenerateLicenseKeyQuickVerifyRoundTrip(t *testing.T) {
+	key, err := GenerateLicenseKey("acme", false)
+	if err != nil {
+		t.Fatalf("GenerateLicenseKey: %v", err)
+	}
+	if !QuickVerifyLicenseKey(key, "acme") {
+		t.Fatalf("expected %q to quick-verify for product %q", key, "acme")
+	}
+	if QuickVerifyLicenseKey(key, "other-product") {
+		t.Fatalf("expected %q to fail quick-verify for a different product", key)
+	}
+}
+
+func TestGenerateLicenseKeyCrockfordEncoding(t *testing.T) {
+	key, err := GenerateLicenseKey("acme", true)
+	if err != nil {
+		t.Fatalf("GenerateLicenseKey: %v", err)
+	}
+	if !QuickVerifyLicenseKey(key, "acme") {
+		t.Fatalf("expected Crockford-encoded key %q to quick-verify", key)
+	}
+}
+
+func TestQuickVerifyLicenseKeyRejectsTamperedChecksum(t *testing.T) {
+	key, err := GenerateLicenseKey("acme", false)
+	if err != nil {
+		t.Fatalf("GenerateLicenseKey: %v", err)
+	}
+
+	tampered := key[:len(key)-1] + "9"
+	if key[len(key)-1] == '9' {
+		tampered = key[:len(key)-1] + "8"
+	}
+	if QuickVerifyLicenseKey(tampered, "acme") {
+		t.Fatalf("expected tampered checksum %q to fail quick-verify", tampered)
+	}
+}
+
+func TestQuickVerifyLicenseKeyPassesLegacyFormat(t *testing.T) {
+	if !QuickVerifyLicenseKey("ABCD-1234-EFGH-5678", "acme") {
+		t.Fatal("expected a legacy 4-group key to quick-verify as not self-describing")
+	}
+}
+
+func TestProductPrefixPadsShortProducts(t *testing.T) {
+	if got := productPrefix("ab"); got != "ABXX" {
+		t.Fatalf("expected %q, got %q", "ABXX", got)
+	}
+}
@@ -0,0 +1,325 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultKeyOverlap is how long a retired signing key keeps verifying
+// already-issued tokens after FileKeyProvider.Rotate replaces it, when
+// JWTConfig.KeyOverlapDuration's caller passes no override.
+const defaultKeyOverlap = 24 * time.Hour
+
+// KeyProvider supplies the signing key GenerateJWT issues new tokens with
+// and every key ParseJWT may still need to verify one already issued,
+// indexed by kid. That indirection is what lets an operator rotate the
+// active key (see FileKeyProvider.Rotate) without invalidating sessions
+// signed under the previous one, and lets GET /.well-known/jwks.json
+// publish every currently-valid verification key to peer services.
+type KeyProvider interface {
+	// SigningKey returns the current kid and the Ed25519 private key new
+	// tokens are signed with.
+	SigningKey() (kid string, priv ed25519.PrivateKey, err error)
+	// VerificationKey returns the Ed25519 public key registered under
+	// kid, or false if kid is unknown — never issued, or pruned after
+	// its overlap window.
+	VerificationKey(kid string) (pub ed25519.PublicKey, ok bool)
+	// JWKSKeys returns every currently-published verification key, for
+	// GET /.well-known/jwks.json.
+	JWKSKeys() []JWKSKey
+}
+
+// JWKSKey is the JSON representation of one verification key in
+// GET /.well-known/jwks.json: an Ed25519 key encoded per RFC 8037 (OKP).
+type JWKSKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	X   string `json:"x"`
+}
+
+func jwksKeyFor(kid string, pub ed25519.PublicKey) JWKSKey {
+	return JWKSKey{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		Kid: kid,
+		Use: "sig",
+		Alg: "EdDSA",
+		X:   base64.RawURLEncoding.EncodeToString(pub),
+	}
+}
+
+// EphemeralKeyProvider is a single Ed25519 keypair generated once at
+// process start, used as GenerateJWT/ParseJWT's default KeyProvider
+// before SetKeyProvider installs a durable one (see FileKeyProvider).
+// Unlike the hardcoded HS256 secret this replaced, nothing here is
+// checked into source — the tradeoff is that every session is
+// invalidated on restart, since the key isn't persisted anywhere.
+type EphemeralKeyProvider struct {
+	kid  string
+	priv ed25519.PrivateKey
+	pub  ed25519.PublicKey
+}
+
+// NewEphemeralKeyProvider generates a fresh Ed25519 keypair.
+func NewEphemeralKeyProvider() (*EphemeralKeyProvider, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := randomKid()
+	if err != nil {
+		return nil, err
+	}
+	return &EphemeralKeyProvider{kid: kid, priv: priv, pub: pub}, nil
+}
+
+func (p *EphemeralKeyProvider) SigningKey() (string, ed25519.PrivateKey, error) {
+	return p.kid, p.priv, nil
+}
+
+func (p *EphemeralKeyProvider) VerificationKey(kid string) (ed25519.PublicKey, bool) {
+	if kid != p.kid {
+		return nil, false
+	}
+	return p.pub, true
+}
+
+func (p *EphemeralKeyProvider) JWKSKeys() []JWKSKey {
+	return []JWKSKey{jwksKeyFor(p.kid, p.pub)}
+}
+
+// FileKeyProvider is the default durable KeyProvider: a directory of
+// Ed25519 keypairs, one per kid, following the same <kid>.key /
+// <kid>.key.pub convention license.GenerateKeyFiles uses. Exactly one kid
+// is active (used to sign new tokens); the rest are retired but still
+// accepted for verification until they've been retired for longer than
+// overlap, at which point Rotate prunes them from disk. The active kid is
+// tracked in dir/active so a restart doesn't silently start signing under
+// a different key.
+type FileKeyProvider struct {
+	dir     string
+	overlap time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]ed25519.PrivateKey
+	activeKid string
+	retiredAt map[string]time.Time
+}
+
+// LoadFileKeyProvider loads every <kid>.key keypair under dir. If dir is
+// empty or doesn't exist yet, it is created and seeded with one freshly
+// generated keypair, so an operator can point this at an empty directory
+// on first deploy.
+func LoadFileKeyProvider(dir string, overlap time.Duration) (*FileKeyProvider, error) {
+	if overlap <= 0 {
+		overlap = defaultKeyOverlap
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("anahtar dizini oluşturulamadı: %w", err)
+	}
+
+	p := &FileKeyProvider{
+		dir:       dir,
+		overlap:   overlap,
+		keys:      make(map[string]ed25519.PrivateKey),
+		retiredAt: make(map[string]time.Time),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("anahtar dizini okunamadı: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".key") {
+			continue
+		}
+		kid := strings.TrimSuffix(name, ".key")
+		priv, err := readEd25519PrivateKey(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("anahtar %q okunamadı: %w", kid, err)
+		}
+		p.keys[kid] = priv
+	}
+
+	if len(p.keys) == 0 {
+		if _, err := p.generateAndStore(); err != nil {
+			return nil, err
+		}
+	}
+
+	active, err := os.ReadFile(filepath.Join(dir, "active"))
+	activeKid := strings.TrimSpace(string(active))
+	if err != nil || p.keys[activeKid] == nil {
+		activeKid = p.latestKid()
+	}
+	p.activeKid = activeKid
+	if err := p.writeActive(activeKid); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *FileKeyProvider) SigningKey() (string, ed25519.PrivateKey, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	priv, ok := p.keys[p.activeKid]
+	if !ok {
+		return "", nil, fmt.Errorf("etkin kid %q için anahtar bulunamadı", p.activeKid)
+	}
+	return p.activeKid, priv, nil
+}
+
+func (p *FileKeyProvider) VerificationKey(kid string) (ed25519.PublicKey, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	priv, ok := p.keys[kid]
+	if !ok {
+		return nil, false
+	}
+	return priv.Public().(ed25519.PublicKey), true
+}
+
+func (p *FileKeyProvider) JWKSKeys() []JWKSKey {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	kids := make([]string, 0, len(p.keys))
+	for kid := range p.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := make([]JWKSKey, 0, len(kids))
+	for _, kid := range kids {
+		jwks = append(jwks, jwksKeyFor(kid, p.keys[kid].Public().(ed25519.PublicKey)))
+	}
+	return jwks
+}
+
+// Rotate generates a fresh Ed25519 keypair, makes it the active signing
+// key, and retires the previous active kid — it keeps verifying tokens
+// already in flight until it has been retired for longer than p.overlap,
+// at which point the next Rotate prunes it from both memory and disk.
+// The swap is atomic: a concurrent SigningKey/VerificationKey call never
+// observes a half-updated state.
+func (p *FileKeyProvider) Rotate() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pruneLocked()
+
+	newKid, err := p.generateAndStoreLocked()
+	if err != nil {
+		return "", err
+	}
+
+	if p.activeKid != "" {
+		p.retiredAt[p.activeKid] = time.Now()
+	}
+	p.activeKid = newKid
+
+	if err := p.writeActive(newKid); err != nil {
+		return "", err
+	}
+	return newKid, nil
+}
+
+// pruneLocked removes every kid retired for longer than p.overlap, from
+// both p.keys and disk. Callers must hold p.mu.
+func (p *FileKeyProvider) pruneLocked() {
+	for kid, retiredAt := range p.retiredAt {
+		if time.Since(retiredAt) <= p.overlap {
+			continue
+		}
+		delete(p.keys, kid)
+		delete(p.retiredAt, kid)
+		_ = os.Remove(filepath.Join(p.dir, kid+".key"))
+		_ = os.Remove(filepath.Join(p.dir, kid+".key.pub"))
+	}
+}
+
+func (p *FileKeyProvider) generateAndStore() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.generateAndStoreLocked()
+}
+
+func (p *FileKeyProvider) generateAndStoreLocked() (string, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("anahtar üretilemedi: %w", err)
+	}
+	kid, err := randomKid()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(p.dir, kid+".key"), []byte(base64.StdEncoding.EncodeToString(priv)), 0o600); err != nil {
+		return "", fmt.Errorf("private key yazılamadı: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(p.dir, kid+".key.pub"), []byte(base64.StdEncoding.EncodeToString(pub)), 0o644); err != nil {
+		return "", fmt.Errorf("public key yazılamadı: %w", err)
+	}
+
+	p.keys[kid] = priv
+	return kid, nil
+}
+
+func (p *FileKeyProvider) writeActive(kid string) error {
+	if err := os.WriteFile(filepath.Join(p.dir, "active"), []byte(kid), 0o644); err != nil {
+		return fmt.Errorf("etkin kid yazılamadı: %w", err)
+	}
+	return nil
+}
+
+// latestKid picks a deterministic kid out of p.keys when dir/active is
+// missing or stale, e.g. on first load against a directory seeded by hand.
+// Callers must hold p.mu (or call before p is shared).
+func (p *FileKeyProvider) latestKid() string {
+	var latest string
+	for kid := range p.keys {
+		if kid > latest {
+			latest = kid
+		}
+	}
+	return latest
+}
+
+func readEd25519PrivateKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("base64 çözülemedi: %w", err)
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("beklenmeyen anahtar uzunluğu: %d (beklenen %d)", len(key), ed25519.PrivateKeySize)
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// randomKid returns a time-prefixed random kid, sortable so latestKid and
+// JWKSKeys produce a stable, most-recent-last order.
+func randomKid() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(b)), nil
+}
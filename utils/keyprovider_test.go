@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileKeyProviderRotatePreservesOldVerification(t *testing.T) {
+	dir := t.TempDir()
+	p, err := LoadFileKeyProvider(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadFileKeyProvider: %v", err)
+	}
+
+	oldKid, oldPub, err := p.SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey: %v", err)
+	}
+	_ = oldPub
+
+	newKid, err := p.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if newKid == oldKid {
+		t.Fatal("expected Rotate to produce a new kid")
+	}
+
+	activeKid, _, err := p.SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey after rotate: %v", err)
+	}
+	if activeKid != newKid {
+		t.Fatalf("expected active kid %q, got %q", newKid, activeKid)
+	}
+
+	if _, ok := p.VerificationKey(oldKid); !ok {
+		t.Fatal("expected the retired kid to still verify within its overlap window")
+	}
+}
+
+func TestFileKeyProviderPrunesAfterOverlap(t *testing.T) {
+	dir := t.TempDir()
+	p, err := LoadFileKeyProvider(dir, 0)
+	if err != nil {
+		t.Fatalf("LoadFileKeyProvider: %v", err)
+	}
+	p.overlap = -time.Second // force the first kid to be immediately prunable
+
+	oldKid, _, err := p.SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey: %v", err)
+	}
+
+	if _, err := p.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if _, err := p.Rotate(); err != nil {
+		t.Fatalf("second Rotate: %v", err)
+	}
+
+	if _, ok := p.VerificationKey(oldKid); ok {
+		t.Fatal("expected the original kid to be pruned after its overlap window elapsed")
+	}
+}
+
+func TestFileKeyProviderReloadsFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	p, err := LoadFileKeyProvider(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadFileKeyProvider: %v", err)
+	}
+	kid, _, err := p.SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey: %v", err)
+	}
+
+	reloaded, err := LoadFileKeyProvider(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("reloading LoadFileKeyProvider: %v", err)
+	}
+	activeKid, _, err := reloaded.SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey on reload: %v", err)
+	}
+	if activeKid != kid {
+		t.Fatalf("expected the active kid to survive a reload, got %q want %q", activeKid, kid)
+	}
+}
+
+func TestJWKSKeysIncludesEveryUnprunedKid(t *testing.T) {
+	dir := t.TempDir()
+	p, err := LoadFileKeyProvider(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadFileKeyProvider: %v", err)
+	}
+	if _, err := p.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	keys := p.JWKSKeys()
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 published keys after one rotation, got %d", len(keys))
+	}
+}
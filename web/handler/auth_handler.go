@@ -2,9 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
-	"license-server/utils"
+	"license-server/web/middleware"
 	"license-server/web/service"
 )
 
@@ -41,21 +42,16 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewDecoder(r.Body).Decode(&body)
 
-	user, err := h.authService.Login(body.Email, body.Password)
+	user, access, refresh, err := h.authService.Login(body.Email, body.Password, clientIP(r), r.UserAgent())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
-	token, err := utils.GenerateJWT(user.ID)
-	if err != nil {
-		http.Error(w, "Token oluşturulamadı", http.StatusInternalServerError)
-		return
-	}
-
 	json.NewEncoder(w).Encode(map[string]any{
-		"message": "Giriş başarılı",
-		"token":   token,
+		"message":       "Giriş başarılı",
+		"token":         access,
+		"refresh_token": refresh,
 		"user": map[string]any{
 			"id":       user.ID,
 			"username": user.Username,
@@ -63,3 +59,91 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 }
+
+// Refresh exchanges a refresh token for a new access token, rotating the
+// refresh token in the process.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	access, refresh, err := h.authService.Refresh(body.RefreshToken, clientIP(r), r.UserAgent())
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, service.ErrSessionReused) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"token":         access,
+		"refresh_token": refresh,
+	})
+}
+
+// Logout revokes the session tied to the presented refresh token. The
+// token's secret must match, not just its session ID, since this endpoint
+// takes no bearer auth.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	if err := h.authService.Logout(body.RefreshToken); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Çıkış yapıldı"})
+}
+
+// Sessions lists the authenticated user's sessions.
+func (h *AuthHandler) Sessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r)
+	if !ok {
+		http.Error(w, "Yetkisiz", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(sessions)
+}
+
+func clientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		return xff
+	}
+	return r.RemoteAddr
+}
+
+// Me returns the authenticated user's profile. It requires JWTAuthMiddleware
+// to have run first so the user ID is present in the request context.
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r)
+	if !ok {
+		http.Error(w, "Yetkisiz", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.authService.GetByID(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":         user.ID,
+		"username":   user.Username,
+		"email":      user.Email,
+		"last_login": user.LastLogin,
+	})
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"license-server/utils"
+)
+
+// ConfigHandler exposes operator endpoints for the live Config.
+type ConfigHandler struct {
+	configPath string
+}
+
+func NewConfigHandler(configPath string) *ConfigHandler {
+	return &ConfigHandler{configPath: configPath}
+}
+
+// Reload re-decodes the config file and, if it validates, swaps it in and
+// runs every utils.OnReload callback. It is the HTTP-triggered equivalent
+// of utils.WatchConfig picking up a file change.
+func (h *ConfigHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if err := utils.ReloadConfig(h.configPath); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "reloaded"})
+}
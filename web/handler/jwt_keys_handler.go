@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"license-server/utils"
+)
+
+// JWKSResponse is the body of GET /.well-known/jwks.json, per RFC 7517.
+type JWKSResponse struct {
+	Keys []utils.JWKSKey `json:"keys"`
+}
+
+// JWKS serves the server's own currently-published JWT verification keys,
+// so a peer service can validate the session tokens this server issues
+// without sharing a secret out of band — the same model Coder and
+// Mattermost use to let cluster nodes verify independently.
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(JWKSResponse{Keys: utils.CurrentKeyProvider().JWKSKeys()})
+}
+
+// RotateJWTKeyResponse is the body of a successful RotateJWTKey call.
+type RotateJWTKeyResponse struct {
+	ActiveKid string `json:"active_kid"`
+}
+
+// RotateJWTKey atomically swaps the active JWT signing kid: POST
+// /api/admin/jwt/rotate, mounted behind admin authentication in
+// web.SetupRoutes. It requires the file-backed KeyProvider (config's
+// jwt.signing_keys_dir) since the ephemeral default has no durable
+// storage to rotate into.
+func RotateJWTKey(w http.ResponseWriter, r *http.Request) {
+	rotator, ok := utils.CurrentKeyProvider().(*utils.FileKeyProvider)
+	if !ok {
+		http.Error(w, "Key rotation not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	kid, err := rotator.Rotate()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RotateJWTKeyResponse{ActiveKid: kid})
+}
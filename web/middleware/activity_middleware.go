@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"license-server/internal/monitoring"
+)
+
+// lastSeenTouch is implemented by web/service.AuthService; kept as a small
+// interface here so the middleware doesn't import the service package.
+type lastSeenTouch interface {
+	TouchLastSeen(userID int) error
+}
+
+// LastSeenTracker debounces last_login writes for authenticated requests.
+// It must run after JWTAuthMiddleware so GetUserID can read the request
+// context; at most one write per user is issued per Interval, using an
+// in-memory set instead of a write on every request.
+type LastSeenTracker struct {
+	svc      lastSeenTouch
+	metrics  *monitoring.Metrics
+	interval time.Duration
+
+	mu   sync.Mutex
+	seen map[int]time.Time
+}
+
+// NewLastSeenTracker creates a tracker that touches last_login via svc at
+// most once per interval, recording each applied touch on metrics.
+func NewLastSeenTracker(svc lastSeenTouch, interval time.Duration, metrics *monitoring.Metrics) *LastSeenTracker {
+	return &LastSeenTracker{
+		svc:      svc,
+		metrics:  metrics,
+		interval: interval,
+		seen:     make(map[int]time.Time),
+	}
+}
+
+// Middleware touches last_login for the authenticated user on this request,
+// at most once per Interval, then calls next unconditionally.
+func (t *LastSeenTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := GetUserID(r); ok {
+			t.touch(userID)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (t *LastSeenTracker) touch(userID int) {
+	now := time.Now()
+
+	t.mu.Lock()
+	last, seen := t.seen[userID]
+	if seen && now.Sub(last) < t.interval {
+		t.mu.Unlock()
+		return
+	}
+	t.seen[userID] = now
+	t.mu.Unlock()
+
+	if err := t.svc.TouchLastSeen(userID); err != nil {
+		return
+	}
+	if t.metrics != nil {
+		t.metrics.UserLastSeenUpdatesTotal.Inc()
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"license-server/utils"
+)
+
+// RequireAdmin gates a route to accounts listed in Config.Admin.UserIDs.
+// It must run after JWTAuthMiddleware so GetUserID can read the request
+// context.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := GetUserID(r)
+		if !ok || !utils.Current().Admin.IsAdmin(userID) {
+			http.Error(w, "Yetkisiz (admin gerekli)", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
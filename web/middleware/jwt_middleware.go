@@ -2,37 +2,218 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
+	"license-server/internal/jwtauth"
 	"license-server/utils"
 )
 
 type contextKey string
 
-const UserIDKey contextKey = "user_id"
+const (
+	UserIDKey contextKey = "user_id"
+	ScopesKey contextKey = "jwt_scopes"
+	RolesKey  contextKey = "jwt_roles"
+)
+
+// Options configures how a JWT auth middleware enforces claims beyond
+// what the Verifier itself already checked (signature, expiry, the
+// issuer's own registered audience): a per-route-group required audience,
+// issuer, or scope.
+type Options struct {
+	RequireAudience string
+	RequireIssuer   string
+	RequireScope    string
+}
+
+// authEngine is the verifier/revocation-checker pair JWTAuthMiddleware
+// authenticates against. It starts out local-only (equivalent to the
+// original HS256-secret-only middleware) and is swapped via
+// ConfigureJWTAuth once config.yml's jwt.issuers / jwt.revocation are
+// decoded, so a JWKS key rotation or newly trusted issuer never requires
+// rebuilding the route tree.
+type authEngine struct {
+	verifier   jwtauth.Verifier
+	revocation jwtauth.RevocationChecker
+}
+
+var enginePtr atomic.Pointer[authEngine]
+
+func init() {
+	enginePtr.Store(&authEngine{verifier: &jwtauth.MultiVerifier{Local: LocalVerifier}})
+}
+
+// ConfigureJWTAuth swaps the verifier/revocation checker JWTAuthMiddleware
+// authenticates against. revocation may be nil to disable the revocation
+// check (the default, since most deployments don't run Redis purely for
+// this).
+func ConfigureJWTAuth(verifier jwtauth.Verifier, revocation jwtauth.RevocationChecker) {
+	enginePtr.Store(&authEngine{verifier: verifier, revocation: revocation})
+}
 
+// JWTAuthMiddleware authenticates a Bearer token against the currently
+// configured engine (see ConfigureJWTAuth) with no per-route enforcement
+// beyond what the token's own issuer/audience already satisfy.
 func JWTAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") {
-			http.Error(w, "Yetkisiz (eksik token)", http.StatusUnauthorized)
-			return
+		e := enginePtr.Load()
+		authenticate(e.verifier, e.revocation, Options{}, w, r, next)
+	})
+}
+
+// RichAuthMiddleware authenticates a Bearer token against verifier
+// (typically a *jwtauth.MultiVerifier combining the local EdDSA session
+// tokens with one or more JWKS-verified external issuers), rejects tokens
+// revocation reports revoked (checked by jti), and enforces opts. Use this
+// directly, instead of the package-wide JWTAuthMiddleware, for a route
+// group that needs a different required audience/issuer/scope than the
+// rest of the API.
+func RichAuthMiddleware(verifier jwtauth.Verifier, revocation jwtauth.RevocationChecker, opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authenticate(verifier, revocation, opts, w, r, next)
+		})
+	}
+}
+
+// authenticate is the shared body behind JWTAuthMiddleware and
+// RichAuthMiddleware. On success it populates UserIDKey (when the
+// token's subject is a local numeric user ID), ScopesKey and RolesKey. On
+// failure it sets a WWW-Authenticate challenge naming the failing check
+// (RFC 6750 §3) and returns 401 for anything about the token itself
+// (malformed, bad signature, expired, wrong audience/issuer, revoked) or
+// 403 for a valid token missing a required scope.
+func authenticate(verifier jwtauth.Verifier, revocation jwtauth.RevocationChecker, opts Options, w http.ResponseWriter, r *http.Request, next http.Handler) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		setChallenge(w, jwtauth.ReasonMalformed, "")
+		http.Error(w, "Yetkisiz (eksik token)", http.StatusUnauthorized)
+		return
+	}
+	tokenStr := strings.TrimPrefix(auth, "Bearer ")
+
+	claims, err := verifier.Verify(r.Context(), tokenStr)
+	if err != nil {
+		reason := jwtauth.ReasonBadSignature
+		var verr *jwtauth.VerifyError
+		if errors.As(err, &verr) {
+			reason = verr.Reason
 		}
+		setChallenge(w, reason, "")
+		http.Error(w, "Geçersiz token", http.StatusUnauthorized)
+		return
+	}
 
-		tokenStr := strings.TrimPrefix(auth, "Bearer ")
-		claims, err := utils.ParseJWT(tokenStr)
+	if opts.RequireIssuer != "" && claims.Issuer != opts.RequireIssuer {
+		setChallenge(w, jwtauth.ReasonUnknownIssuer, "")
+		http.Error(w, "Geçersiz token (issuer)", http.StatusUnauthorized)
+		return
+	}
+	if opts.RequireAudience != "" && !containsString(claims.Audience, opts.RequireAudience) {
+		setChallenge(w, jwtauth.ReasonWrongAudience, "")
+		http.Error(w, "Geçersiz token (audience)", http.StatusUnauthorized)
+		return
+	}
+
+	if revocation != nil {
+		revoked, err := revocation.IsRevoked(r.Context(), claims.ID)
 		if err != nil {
-			http.Error(w, "Geçersiz token", http.StatusUnauthorized)
+			// Fail closed here, unlike ratelimit's fail-open: an outage
+			// checking revocation must not let a possibly-revoked token
+			// through.
+			http.Error(w, "Yetkisiz (iptal kontrolü başarısız)", http.StatusUnauthorized)
 			return
 		}
+		if revoked {
+			setChallenge(w, jwtauth.ReasonRevoked, "")
+			http.Error(w, "Geçersiz token (iptal edildi)", http.StatusUnauthorized)
+			return
+		}
+	}
 
-		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	if opts.RequireScope != "" && !claims.HasScope(opts.RequireScope) {
+		setChallenge(w, jwtauth.ReasonInsufficientScope, opts.RequireScope)
+		http.Error(w, "Yetkisiz (eksik yetki)", http.StatusForbidden)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), ScopesKey, claims.Scopes)
+	ctx = context.WithValue(ctx, RolesKey, claims.Roles)
+	if userID, err := strconv.Atoi(claims.Subject); err == nil {
+		ctx = context.WithValue(ctx, UserIDKey, userID)
+	}
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// setChallenge sets the WWW-Authenticate header per RFC 6750 §3, naming
+// reason so a client (or an engineer reading a failed curl -v) can see
+// exactly why a token was rejected without needing server-side logs.
+func setChallenge(w http.ResponseWriter, reason jwtauth.Reason, scope string) {
+	errorCode := "invalid_token"
+	var extra string
+	if reason == jwtauth.ReasonInsufficientScope {
+		errorCode = "insufficient_scope"
+		if scope != "" {
+			extra = fmt.Sprintf(`, scope="%s"`, scope)
+		}
+	}
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error=%q, error_description=%q%s`, errorCode, string(reason), extra))
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
 
 func GetUserID(r *http.Request) (int, bool) {
 	id, ok := r.Context().Value(UserIDKey).(int)
 	return id, ok
 }
+
+// GetScopes returns the scopes carried by the token that authenticated r,
+// if any.
+func GetScopes(r *http.Request) []string {
+	scopes, _ := r.Context().Value(ScopesKey).([]string)
+	return scopes
+}
+
+// GetRoles returns the roles carried by the token that authenticated r,
+// if any.
+func GetRoles(r *http.Request) []string {
+	roles, _ := r.Context().Value(RolesKey).([]string)
+	return roles
+}
+
+// localVerifier adapts utils.ParseJWT — the server's own EdDSA-signed
+// session tokens — to the jwtauth.Verifier interface so it can run
+// alongside JWKS-verified external issuers behind a MultiVerifier.
+type localVerifier struct{}
+
+// LocalVerifier is the jwtauth.Verifier for the server's own session
+// tokens, used as MultiVerifier.Local by ConfigureJWTAuth's default and
+// config-driven engines.
+var LocalVerifier jwtauth.Verifier = localVerifier{}
+
+func (localVerifier) Verify(_ context.Context, tokenString string) (*jwtauth.Claims, error) {
+	claims, err := utils.ParseJWT(tokenString)
+	if err != nil {
+		return nil, jwtauth.ClassifyParseError(err)
+	}
+	if claims == nil {
+		return nil, &jwtauth.VerifyError{Reason: jwtauth.ReasonBadSignature, Err: errors.New("token failed validation")}
+	}
+
+	return &jwtauth.Claims{
+		Subject: strconv.Itoa(claims.UserID),
+		ID:      claims.ID,
+	}, nil
+}
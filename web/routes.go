@@ -3,17 +3,23 @@ package web
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 
+	"license-server/internal/monitoring"
+	"license-server/license"
 	"license-server/web/handler"
+	"license-server/web/middleware"
 	"license-server/web/service"
 )
 
-func SetupRoutes(db *sql.DB) http.Handler {
+func SetupRoutes(db *sql.DB, metrics *monitoring.Metrics, lastSeenInterval time.Duration, logger *monitoring.Logger, reproducerCfg monitoring.ReproducerConfig, configPath string, licenseHandler *license.Handler) http.Handler {
 	r := chi.NewRouter()
 
+	r.Use(monitoring.HTTPMiddleware(metrics))
+	r.Use(monitoring.RequestTracingMiddleware(logger, reproducerCfg))
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
@@ -24,13 +30,37 @@ func SetupRoutes(db *sql.DB) http.Handler {
 	}))
 
 	r.Get("/api/ping", handler.PingHandler)
+	r.Get("/api/entitlements", licenseHandler.GetEntitlements)
+	r.Post("/api/license/activate", licenseHandler.ActivateTicket)
+	r.Get("/.well-known/jwks.json", handler.JWKS)
 
-	authService := service.NewAuthService(db)
+	authService := service.NewAuthService(db, metrics)
 	authHandler := handler.NewAuthHandler(authService)
 
 	r.Route("/api/auth", func(auth chi.Router) {
 		auth.Post("/register", authHandler.Register)
 		auth.Post("/login", authHandler.Login)
+		auth.Post("/refresh", authHandler.Refresh)
+		auth.Post("/logout", authHandler.Logout)
+
+		lastSeen := middleware.NewLastSeenTracker(authService, lastSeenInterval, metrics)
+		auth.With(middleware.JWTAuthMiddleware, lastSeen.Middleware).Get("/me", authHandler.Me)
+		auth.With(middleware.JWTAuthMiddleware, lastSeen.Middleware).Get("/sessions", authHandler.Sessions)
+	})
+
+	configHandler := handler.NewConfigHandler(configPath)
+	r.Route("/api/admin", func(admin chi.Router) {
+		admin.With(middleware.JWTAuthMiddleware, middleware.RequireAdmin).Post("/config/reload", configHandler.Reload)
+		admin.With(middleware.JWTAuthMiddleware, middleware.RequireAdmin).Post("/jwt/rotate", handler.RotateJWTKey)
+	})
+
+	r.Route("/api/license", func(lic chi.Router) {
+		lic.Use(middleware.JWTAuthMiddleware, middleware.RequireAdmin)
+		lic.Post("/", licenseHandler.UploadLicense)
+		lic.Delete("/{id}", licenseHandler.DeleteLicenseByID)
+		lic.Post("/tickets/{id}/revoke", licenseHandler.RevokeActivationTicket)
+		lic.Post("/import", licenseHandler.ImportBundle)
+		lic.Get("/export", licenseHandler.ExportBundle)
 	})
 
 	return r
@@ -3,16 +3,19 @@ package service
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"license-server/database"
+	"license-server/internal/monitoring"
 	"license-server/utils"
 )
 
 type AuthService struct {
-	db *sql.DB
+	db      *sql.DB
+	metrics *monitoring.Metrics
 }
 
-func NewAuthService(db *sql.DB) *AuthService {
-	return &AuthService{db: db}
+func NewAuthService(db *sql.DB, metrics *monitoring.Metrics) *AuthService {
+	return &AuthService{db: db, metrics: metrics}
 }
 
 func (s *AuthService) Register(username, email, password, passwordRepeat string) error {
@@ -32,7 +35,7 @@ func (s *AuthService) Register(username, email, password, passwordRepeat string)
 	return err
 }
 
-func (s *AuthService) Login(email, password string) (*database.Account, error) {
+func (s *AuthService) Login(email, password, ip, userAgent string) (*database.Account, string, string, error) {
 	row := s.db.QueryRow(`
 		SELECT id, username, email, password_hash, created_at, last_login, last_login_ip
 		FROM Accounts WHERE email = ?`, email)
@@ -40,14 +43,42 @@ func (s *AuthService) Login(email, password string) (*database.Account, error) {
 	var acc database.Account
 	err := row.Scan(&acc.ID, &acc.Username, &acc.Email, &acc.PasswordHash, &acc.CreatedAt, &acc.LastLogin, &acc.LastLoginIP)
 	if err != nil {
-		return nil, errors.New("e-posta veya şifre hatalı")
+		return nil, "", "", errors.New("e-posta veya şifre hatalı")
 	}
 
 	if !utils.CheckPasswordHash(password, acc.PasswordHash) {
-		return nil, errors.New("e-posta veya şifre hatalı")
+		return nil, "", "", errors.New("e-posta veya şifre hatalı")
 	}
 
 	_, _ = s.db.Exec(`UPDATE Accounts SET last_login = NOW() WHERE id = ?`, acc.ID)
 
+	access, refresh, err := s.createSession(acc.ID, ip, userAgent)
+	if err != nil {
+		s.recordSessionCreated("error")
+		return nil, "", "", fmt.Errorf("oturum oluşturulamadı: %w", err)
+	}
+	s.recordSessionCreated("ok")
+
+	return &acc, access, refresh, nil
+}
+
+// GetByID fetches an account by its ID, used by the authenticated /me route.
+func (s *AuthService) GetByID(userID int) (*database.Account, error) {
+	row := s.db.QueryRow(`
+		SELECT id, username, email, password_hash, created_at, last_login, last_login_ip
+		FROM Accounts WHERE id = ?`, userID)
+
+	var acc database.Account
+	if err := row.Scan(&acc.ID, &acc.Username, &acc.Email, &acc.PasswordHash, &acc.CreatedAt, &acc.LastLogin, &acc.LastLoginIP); err != nil {
+		return nil, errors.New("kullanıcı bulunamadı")
+	}
 	return &acc, nil
 }
+
+// TouchLastSeen stamps last_login for userID to the current time. It is
+// called by the activity middleware on authenticated requests, debounced
+// so it does not issue a write per request.
+func (s *AuthService) TouchLastSeen(userID int) error {
+	_, err := s.db.Exec(`UPDATE Accounts SET last_login = NOW() WHERE id = ?`, userID)
+	return err
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"license-server/database"
+	"license-server/utils"
+)
+
+func generateAccessToken(userID int) (string, error) {
+	return utils.GenerateJWTWithTTL(userID, accessTokenTTL)
+}
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExpired  = errors.New("session expired")
+	ErrSessionReused   = errors.New("refresh token reused")
+)
+
+// createSession persists a new session row for userID and returns a fresh
+// access JWT plus the opaque refresh token (the caller sees the raw token;
+// only its hash is stored).
+func (s *AuthService) createSession(userID int, ip, userAgent string) (access, refresh string, err error) {
+	sessionID, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO sessions (id, user_id, token_hash, expires_at, ip, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		sessionID, userID, hashToken(refreshToken), time.Now().Add(refreshTokenTTL), ip, userAgent,
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	access, err = generateAccessToken(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.refreshSessionsActiveGauge()
+	return access, sessionID + "." + refreshToken, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and rotates the
+// refresh token. If a previously-revoked refresh token is presented (reuse
+// of a stolen or already-rotated token), the entire session is revoked and
+// ErrSessionReused is returned so the caller can force re-authentication.
+func (s *AuthService) Refresh(refreshToken, ip, userAgent string) (access, newRefresh string, err error) {
+	sessionID, secret, ok := splitRefreshToken(refreshToken)
+	if !ok {
+		s.recordTokenRefresh("invalid")
+		return "", "", ErrSessionNotFound
+	}
+
+	sess, err := s.getSession(sessionID)
+	if err != nil {
+		s.recordTokenRefresh("invalid")
+		return "", "", err
+	}
+
+	if sess.RevokedAt != nil {
+		if err := s.revokeSession(sessionID, "reuse_detected"); err != nil {
+			return "", "", err
+		}
+		if err := s.LogoutAll(sess.UserID); err != nil {
+			return "", "", err
+		}
+		s.recordSessionReuseDetected()
+		s.recordTokenRefresh("reused")
+		return "", "", ErrSessionReused
+	}
+
+	secretHash := hashToken(secret)
+	if subtle.ConstantTimeCompare([]byte(secretHash), []byte(sess.TokenHash)) != 1 {
+		// The token doesn't match the session's current secret. If it
+		// matches the secret that was rotated away from last time, this
+		// is a stale refresh token being replayed (e.g. an attacker's
+		// stolen copy racing the legitimate client's next refresh) — the
+		// exact case this feature exists to catch, even though the
+		// session itself was never explicitly revoked. Anything else is
+		// just a bad token.
+		if sess.PrevTokenHash != nil && subtle.ConstantTimeCompare([]byte(secretHash), []byte(*sess.PrevTokenHash)) == 1 {
+			if err := s.revokeSession(sessionID, "reuse_detected"); err != nil {
+				return "", "", err
+			}
+			if err := s.LogoutAll(sess.UserID); err != nil {
+				return "", "", err
+			}
+			s.recordSessionReuseDetected()
+			s.recordTokenRefresh("reused")
+			return "", "", ErrSessionReused
+		}
+		s.recordTokenRefresh("invalid")
+		return "", "", ErrSessionNotFound
+	}
+
+	if time.Now().After(sess.ExpiresAt) {
+		s.recordTokenRefresh("expired")
+		return "", "", ErrSessionExpired
+	}
+
+	newSecret, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = s.db.Exec(
+		`UPDATE sessions SET prev_token_hash = token_hash, token_hash = ?, expires_at = ?, ip = ?, user_agent = ? WHERE id = ?`,
+		hashToken(newSecret), time.Now().Add(refreshTokenTTL), ip, userAgent, sessionID,
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	access, err = generateAccessToken(sess.UserID)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.recordTokenRefresh("ok")
+	return access, sessionID + "." + newSecret, nil
+}
+
+// Logout revokes the session identified by refreshToken, e.g. the one tied
+// to the device making the request. The token's secret half must match the
+// session's current hash, not just its ID, so knowing or guessing a session
+// ID alone can't be used to force another user's session closed.
+func (s *AuthService) Logout(refreshToken string) error {
+	sessionID, secret, ok := splitRefreshToken(refreshToken)
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	sess, err := s.getSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(sess.TokenHash)) != 1 {
+		return ErrSessionNotFound
+	}
+
+	return s.revokeSession(sessionID, "logout")
+}
+
+// LogoutAll revokes every active session belonging to userID, used both for
+// an explicit "sign out everywhere" action and for reuse-detection cleanup.
+func (s *AuthService) LogoutAll(userID int) error {
+	rows, err := s.db.Query(`SELECT id FROM sessions WHERE user_id = ? AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := s.revokeSession(id, "logout_all"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSessions returns every session (active and revoked) for userID,
+// newest first, for the /auth/sessions endpoint.
+func (s *AuthService) ListSessions(userID int) ([]database.Session, error) {
+	rows, err := s.db.Query(
+		`SELECT id, user_id, token_hash, created_at, expires_at, revoked_at, ip, user_agent
+		FROM sessions WHERE user_id = ? ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []database.Session
+	for rows.Next() {
+		var sess database.Session
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&sess.ID, &sess.UserID, &sess.TokenHash, &sess.CreatedAt, &sess.ExpiresAt, &revokedAt, &sess.IP, &sess.UserAgent); err != nil {
+			return nil, err
+		}
+		if revokedAt.Valid {
+			sess.RevokedAt = &revokedAt.Time
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *AuthService) getSession(sessionID string) (*database.Session, error) {
+	row := s.db.QueryRow(
+		`SELECT id, user_id, token_hash, prev_token_hash, created_at, expires_at, revoked_at, ip, user_agent
+		FROM sessions WHERE id = ?`,
+		sessionID,
+	)
+
+	var sess database.Session
+	var prevTokenHash sql.NullString
+	var revokedAt sql.NullTime
+	err := row.Scan(&sess.ID, &sess.UserID, &sess.TokenHash, &prevTokenHash, &sess.CreatedAt, &sess.ExpiresAt, &revokedAt, &sess.IP, &sess.UserAgent)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if prevTokenHash.Valid {
+		sess.PrevTokenHash = &prevTokenHash.String
+	}
+	if revokedAt.Valid {
+		sess.RevokedAt = &revokedAt.Time
+	}
+	return &sess, nil
+}
+
+func (s *AuthService) revokeSession(sessionID, reason string) error {
+	_, err := s.db.Exec(`UPDATE sessions SET revoked_at = NOW() WHERE id = ? AND revoked_at IS NULL`, sessionID)
+	if err != nil {
+		return err
+	}
+	s.recordSessionRevoked(reason)
+	s.refreshSessionsActiveGauge()
+	return nil
+}
+
+// refreshSessionsActiveGauge recomputes the sessions_active gauge. Sessions
+// churn slowly enough (one write per login/refresh/logout) that a
+// synchronous COUNT on each mutation is simpler than a background ticker.
+func (s *AuthService) refreshSessionsActiveGauge() {
+	if s.metrics == nil {
+		return
+	}
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE revoked_at IS NULL AND expires_at > NOW()`).Scan(&count)
+	if err != nil {
+		return
+	}
+	s.metrics.SessionsActive.Set(float64(count))
+}
+
+func (s *AuthService) recordSessionCreated(result string) {
+	if s.metrics != nil {
+		s.metrics.RecordSessionCreated(result)
+	}
+}
+
+func (s *AuthService) recordSessionRevoked(reason string) {
+	if s.metrics != nil {
+		s.metrics.RecordSessionRevoked(reason)
+	}
+}
+
+func (s *AuthService) recordTokenRefresh(result string) {
+	if s.metrics != nil {
+		s.metrics.RecordTokenRefresh(result)
+	}
+}
+
+func (s *AuthService) recordSessionReuseDetected() {
+	if s.metrics != nil {
+		s.metrics.RecordSessionReuseDetected()
+	}
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitRefreshToken parses a "<sessionID>.<secret>" refresh token as issued
+// by createSession/Refresh.
+func splitRefreshToken(token string) (sessionID, secret string, ok bool) {
+	for i := 0; i < len(token); i++ {
+		if token[i] == '.' {
+			return token[:i], token[i+1:], true
+		}
+	}
+	return "", "", false
+}
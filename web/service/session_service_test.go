@@ -0,0 +1,198 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+// sessionRow builds the row getSession's query would return for a session
+// with the given hashes, mirroring the columns session_service.go selects.
+func sessionRow(id string, userID int, tokenHash, prevTokenHash string, expiresAt time.Time) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "token_hash", "prev_token_hash", "created_at", "expires_at", "revoked_at", "ip", "user_agent"})
+	var prev interface{}
+	if prevTokenHash != "" {
+		prev = prevTokenHash
+	}
+	rows.AddRow(id, userID, tokenHash, prev, time.Now(), expiresAt, nil, "127.0.0.1", "test-agent")
+	return rows
+}
+
+// TestAuthServiceRefreshRotatesTokenOnValidSecret is the happy path: a
+// refresh token matching the session's current secret rotates it and
+// returns a new one, without touching reuse detection at all.
+func TestAuthServiceRefreshRotatesTokenOnValidSecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	s := &AuthService{db: db}
+	sessionID := "sess1"
+
+	mock.ExpectQuery("SELECT id, user_id, token_hash, prev_token_hash").
+		WillReturnRows(sessionRow(sessionID, 7, hashToken("current-secret"), "", time.Now().Add(time.Hour)))
+	mock.ExpectExec("UPDATE sessions SET prev_token_hash = token_hash, token_hash = ").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "127.0.0.1", "test-agent", sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	access, newRefresh, err := s.Refresh(sessionID+".current-secret", "127.0.0.1", "test-agent")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if access == "" {
+		t.Fatal("expected a non-empty access token")
+	}
+	wantPrefix := sessionID + "."
+	if len(newRefresh) <= len(wantPrefix) || newRefresh[:len(wantPrefix)] != wantPrefix {
+		t.Fatalf("expected rotated refresh token to start with %q, got %q", wantPrefix, newRefresh)
+	}
+	if newRefresh == sessionID+".current-secret" {
+		t.Fatal("expected the refresh token's secret to actually rotate")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+// TestAuthServiceRefreshDetectsStaleTokenReplay guards against the gap
+// where replaying an already-rotated refresh token (not an explicitly
+// revoked one) went undetected: it just failed ConstantTimeCompare like
+// any other bad token, leaving the legitimate session alive. A mismatch
+// against the current hash that matches the retained previous hash must
+// be treated as reuse: the session revoked and every session for the user
+// logged out.
+func TestAuthServiceRefreshDetectsStaleTokenReplay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	s := &AuthService{db: db}
+	sessionID := "sess1"
+	userID := 7
+
+	mock.ExpectQuery("SELECT id, user_id, token_hash, prev_token_hash").
+		WillReturnRows(sessionRow(sessionID, userID, hashToken("current-secret"), hashToken("stale-secret"), time.Now().Add(time.Hour)))
+	mock.ExpectExec("UPDATE sessions SET revoked_at = NOW").
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id FROM sessions WHERE user_id = \? AND revoked_at IS NULL`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(sessionID))
+	mock.ExpectExec("UPDATE sessions SET revoked_at = NOW").
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, _, err = s.Refresh(sessionID+".stale-secret", "127.0.0.1", "test-agent")
+	if err != ErrSessionReused {
+		t.Fatalf("expected ErrSessionReused, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+// TestAuthServiceRefreshRejectsUnrelatedToken confirms a token that matches
+// neither the current nor the previous hash is just rejected as invalid,
+// with no revocation side effects.
+func TestAuthServiceRefreshRejectsUnrelatedToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	s := &AuthService{db: db}
+	sessionID := "sess1"
+
+	mock.ExpectQuery("SELECT id, user_id, token_hash, prev_token_hash").
+		WillReturnRows(sessionRow(sessionID, 7, hashToken("current-secret"), hashToken("stale-secret"), time.Now().Add(time.Hour)))
+
+	_, _, err = s.Refresh(sessionID+".garbage", "127.0.0.1", "test-agent")
+	if err != ErrSessionNotFound {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+// TestAuthServiceLogoutRequiresMatchingSecret guards against the gap where
+// Logout revoked by session ID alone: since /api/auth/logout carries no
+// bearer auth, that let anyone who merely knew or guessed a session ID
+// force-logout another user's session. The presented secret must match the
+// session's current hash before it's revoked.
+func TestAuthServiceLogoutRequiresMatchingSecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	s := &AuthService{db: db}
+	sessionID := "sess1"
+
+	mock.ExpectQuery("SELECT id, user_id, token_hash, prev_token_hash").
+		WillReturnRows(sessionRow(sessionID, 7, hashToken("real-secret"), "", time.Now().Add(time.Hour)))
+
+	if err := s.Logout(sessionID + ".wrong-secret"); err != ErrSessionNotFound {
+		t.Fatalf("expected ErrSessionNotFound for a wrong secret, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations (no revoke should have been attempted): %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, user_id, token_hash, prev_token_hash").
+		WillReturnRows(sessionRow(sessionID, 7, hashToken("real-secret"), "", time.Now().Add(time.Hour)))
+	mock.ExpectExec("UPDATE sessions SET revoked_at = NOW").
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.Logout(sessionID + ".real-secret"); err != nil {
+		t.Fatalf("Logout with the correct secret: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations: %v", err)
+	}
+}
+
+// TestAuthServiceLogoutAllRevokesEverySession confirms LogoutAll revokes
+// every one of a user's active sessions, the cleanup step reuse detection
+// relies on.
+func TestAuthServiceLogoutAllRevokesEverySession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	s := &AuthService{db: db}
+	userID := 7
+
+	mock.ExpectQuery(`SELECT id FROM sessions WHERE user_id = \? AND revoked_at IS NULL`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("sess1").AddRow("sess2"))
+	mock.ExpectExec("UPDATE sessions SET revoked_at = NOW").
+		WithArgs("sess1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE sessions SET revoked_at = NOW").
+		WithArgs("sess2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.LogoutAll(userID); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sqlmock expectations: %v", err)
+	}
+}